@@ -0,0 +1,65 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// GetCategories retrieves the valid submission category values from the submission upload form.
+// The result is cached.
+func (c *Client) GetCategories() ([]string, error) {
+	c.categoriesLock.Lock()
+	defer c.categoriesLock.Unlock()
+	if c.categories != nil {
+		return c.categories, nil
+	}
+	log.Debug("Retrieving submission categories")
+
+	root, err := c.get("/submit/")
+	if err != nil {
+		return nil, err
+	}
+
+	coh := &selectOptionsHandler{
+		name: "cat",
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			coh,
+		},
+	}
+	rp.processNode(root)
+
+	if len(coh.values) == 0 {
+		return nil, ErrNotLoggedIn
+	}
+	c.categories = coh.values
+	return c.categories, nil
+}
@@ -29,6 +29,12 @@
 package faapi
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -37,15 +43,29 @@ import (
 	"net/http/cookiejar"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/rehttp"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/html"
+	"golang.org/x/net/http2"
 )
 
 var (
 	ErrNotLoggedIn = errors.New("not logged in")
+	ErrHTTPError   = errors.New("HTTP error")
+	// ErrContentRemoved is returned when a submission does not exist or has been removed.
+	ErrContentRemoved = errors.New("content removed")
+)
+
+const (
+	// baseURL is the URL relative links on FA pages are resolved against.
+	baseURL = "https://www.furaffinity.net/"
+
+	// maxAdaptiveRateLimitFactor is the most the adaptive rate limit will increase the interval between
+	// requests, as a multiple of Config.RateLimit.
+	maxAdaptiveRateLimitFactor = 16
 )
 
 // Client is a FurAffinity client.
@@ -53,11 +73,32 @@ type Client struct {
 	http        http.Client
 	config      Config
 	rateLimiter *time.Ticker
+
+	metricsLock        sync.Mutex
+	rateLimitWaitTotal time.Duration
+
+	rateLimitLock     sync.Mutex
+	rateLimitInterval time.Duration
+
+	userAgentLock  sync.Mutex
+	userAgentIndex int
+
+	usernameLock sync.Mutex
+	username     string
+
+	categoriesLock sync.Mutex
+	categories     []string
 }
 
 // New creates a new Client with the given configuration.
 func New(config Config) (*Client, error) {
-	var tr http.RoundTripper = &http.Transport{}
+	if config.RateLimit <= 0 {
+		return nil, fmt.Errorf("Config.RateLimit must be positive, got %v", config.RateLimit)
+	}
+
+	transport := &http.Transport{
+		DisableCompression: config.DisableCompression,
+	}
 
 	if config.Proxy != "" {
 		purl, err := url.Parse(config.Proxy)
@@ -65,11 +106,20 @@ func New(config Config) (*Client, error) {
 			return nil, err
 		}
 
-		tr = &http.Transport{
-			Proxy: http.ProxyURL(purl),
+		transport.Proxy = http.ProxyURL(purl)
+	}
+
+	if config.EnableHTTP2 {
+		if err := http2.ConfigureTransport(transport); err != nil {
+			return nil, err
 		}
+	} else {
+		// a non-nil empty map prevents HTTP/2 from being negotiated
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
 	}
 
+	var tr http.RoundTripper = transport
+
 	if config.RetryLimit > 0 {
 		if config.RetryDelay <= 0 {
 			config.RetryDelay = 10 * time.Second
@@ -84,7 +134,7 @@ func New(config Config) (*Client, error) {
 		return nil, err
 	}
 
-	curl, err := url.Parse("https://www.furaffinity.net/")
+	curl, err := url.Parse(baseURL)
 	if err != nil {
 		return nil, err
 	}
@@ -97,6 +147,18 @@ func New(config Config) (*Client, error) {
 	}
 	jar.SetCookies(curl, cookies)
 
+	if config.RequestIDHeader != "" && config.RequestIDGenerator == nil {
+		config.RequestIDGenerator = newUUID
+	}
+
+	if config.AdaptiveRateLimit && config.TargetLatency <= 0 {
+		return nil, fmt.Errorf("Config.TargetLatency must be positive with AdaptiveRateLimit, got %v", config.TargetLatency)
+	}
+
+	if config.AcceptLanguage == "" {
+		config.AcceptLanguage = "en-US,en;q=0.9"
+	}
+
 	if config.Timeout == 0 {
 		config.Timeout = 15 * time.Second
 	}
@@ -107,61 +169,228 @@ func New(config Config) (*Client, error) {
 			Timeout:   config.Timeout,
 			Transport: tr,
 		},
-		config:      config,
-		rateLimiter: time.NewTicker(config.RateLimit),
+		config:            config,
+		rateLimiter:       time.NewTicker(config.RateLimit),
+		rateLimitInterval: config.RateLimit,
 	}, nil
 }
 
+// newUUID generates a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		log.WithError(err).Error("Unable to generate UUID")
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
 func (c *Client) Close() {
 	c.rateLimiter.Stop()
 }
 
+// RateLimitWaitTotal returns the total time requests have spent waiting on the rate limiter.
+func (c *Client) RateLimitWaitTotal() time.Duration {
+	c.metricsLock.Lock()
+	defer c.metricsLock.Unlock()
+	return c.rateLimitWaitTotal
+}
+
+// ResetRateLimitMetrics resets the total returned by RateLimitWaitTotal.
+func (c *Client) ResetRateLimitMetrics() {
+	c.metricsLock.Lock()
+	defer c.metricsLock.Unlock()
+	c.rateLimitWaitTotal = 0
+}
+
+func (c *Client) addRateLimitWait(d time.Duration) {
+	c.metricsLock.Lock()
+	defer c.metricsLock.Unlock()
+	c.rateLimitWaitTotal += d
+}
+
 func (c *Client) newRequest(method, uri string, body io.Reader) (*http.Request, error) {
+	return c.newRequestWithContext(context.Background(), method, uri, body)
+}
+
+func (c *Client) newRequestWithContext(ctx context.Context, method, uri string, body io.Reader) (*http.Request, error) {
 	log.WithField("uri", uri).Debug("Creating new request")
 	if !strings.HasPrefix(uri, "https://") {
 		uri = "https://www.furaffinity.net" + uri
 	}
-	req, err := http.NewRequest(method, uri, body)
+	req, err := http.NewRequestWithContext(ctx, method, uri, body)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("User-Agent", c.config.UserAgent)
+	req.Header.Add("User-Agent", c.userAgent())
+	if c.config.AcceptLanguage != "" {
+		req.Header.Add("Accept-Language", c.config.AcceptLanguage)
+	}
+	if c.config.RequestIDHeader != "" {
+		req.Header.Add(c.config.RequestIDHeader, c.config.RequestIDGenerator())
+	}
 	return req, nil
 }
 
+// waitRateLimit blocks until the rate limiter allows another request, or ctx is done.
+func (c *Client) waitRateLimit(ctx context.Context) error {
+	start := time.Now()
+	defer func() {
+		c.addRateLimitWait(time.Since(start))
+	}()
+
+	select {
+	case <-c.rateLimiter.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// adaptRateLimit adjusts the rate limiter interval based on the latency of a request: the interval is
+// doubled when FA is slow, and reduced by a tenth of RateLimit at a time when it is fast (AIMD).
+func (c *Client) adaptRateLimit(latency time.Duration) {
+	c.rateLimitLock.Lock()
+	defer c.rateLimitLock.Unlock()
+
+	interval := c.rateLimitInterval
+	switch {
+	case latency > c.config.TargetLatency*3/2:
+		interval *= 2
+		if interval > maxAdaptiveRateLimitFactor*c.config.RateLimit {
+			interval = maxAdaptiveRateLimitFactor * c.config.RateLimit
+		}
+	case latency < c.config.TargetLatency/2:
+		interval -= c.config.RateLimit / 10
+		if interval < c.config.RateLimit {
+			interval = c.config.RateLimit
+		}
+	}
+
+	if interval != c.rateLimitInterval {
+		log.WithFields(log.Fields{
+			"latency":  latency,
+			"interval": interval,
+		}).Debug("Adjusting rate limit")
+		c.rateLimitInterval = interval
+		c.rateLimiter.Reset(interval)
+	}
+}
+
+// userAgent returns the user agent to use for the next request.
+func (c *Client) userAgent() string {
+	if c.config.UserAgent != "" || len(c.config.UserAgents) == 0 {
+		return c.config.UserAgent
+	}
+
+	c.userAgentLock.Lock()
+	defer c.userAgentLock.Unlock()
+	ua := c.config.UserAgents[c.userAgentIndex]
+	c.userAgentIndex = (c.userAgentIndex + 1) % len(c.config.UserAgents)
+	return ua
+}
+
 func (c *Client) doRaw(req *http.Request) (*http.Response, error) {
 	log.WithFields(log.Fields{
 		"url":    req.URL,
 		"method": req.Method,
 	}).Debug("Making request")
 
-	if req.URL.Host == "www.furaffinity.net" {
-		// wait for rate limiting
-		<-c.rateLimiter.C
+	rateLimited := req.URL.Host == "www.furaffinity.net"
+	if rateLimited {
+		if err := c.waitRateLimit(req.Context()); err != nil {
+			return nil, err
+		}
 	}
 
+	start := time.Now()
 	res, err := c.http.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	if rateLimited && c.config.AdaptiveRateLimit {
+		c.adaptRateLimit(time.Since(start))
+	}
 
-	if res.StatusCode != http.StatusOK {
+	if log.IsLevelEnabled(log.TraceLevel) {
+		log.WithFields(log.Fields{
+			"url":     req.URL,
+			"code":    res.StatusCode,
+			"headers": res.Header,
+		}).Trace("Response headers")
+	}
+
+	// a range request may also find that there is nothing left to retrieve
+	ranged := req.Header.Get("Range") != "" &&
+		(res.StatusCode == http.StatusPartialContent || res.StatusCode == http.StatusRequestedRangeNotSatisfiable)
+	if c.config.MaxResponseBodySize > 0 {
+		res.Body = &limitedBody{
+			Reader: io.LimitReader(res.Body, c.config.MaxResponseBodySize+1),
+			Closer: res.Body,
+			url:    req.URL.String(),
+			max:    c.config.MaxResponseBodySize,
+		}
+	}
+
+	if res.StatusCode != http.StatusOK && !ranged {
+		defer res.Body.Close()
 		bb, _ := ioutil.ReadAll(res.Body)
 		log.WithFields(log.Fields{
 			"url":  req.URL,
 			"code": res.StatusCode,
 			"body": string(bb),
 		}).Debug("Unexpected HTTP response code")
-		return nil, fmt.Errorf("HTTP response %d not expected", res.StatusCode)
+		return nil, fmt.Errorf("%w: HTTP response %d not expected", ErrHTTPError, res.StatusCode)
 	}
 
 	return res, nil
 }
 
+// limitedBody is a response body that fails with ErrHTTPError once more than max bytes are read, and
+// on every read after that
+type limitedBody struct {
+	io.Reader
+	io.Closer
+	url      string
+	max      int64
+	read     int64
+	exceeded bool
+}
+
+func (lb *limitedBody) Read(p []byte) (int, error) {
+	if lb.exceeded {
+		return 0, fmt.Errorf("%w: response body exceeds %d bytes", ErrHTTPError, lb.max)
+	}
+	n, err := lb.Reader.Read(p)
+	lb.read += int64(n)
+	if lb.read > lb.max {
+		lb.exceeded = true
+		log.WithFields(log.Fields{
+			"url":   lb.url,
+			"limit": lb.max,
+		}).Warn("Response body exceeds maximum size")
+		// only return the bytes up to the limit
+		n -= int(lb.read - lb.max)
+		if n < 0 {
+			n = 0
+		}
+		return n, fmt.Errorf("%w: response body exceeds %d bytes", ErrHTTPError, lb.max)
+	}
+	return n, err
+}
+
 func (c *Client) do(req *http.Request) (*html.Node, error) {
+	root, _, err := c.doFinalURL(req)
+	return root, err
+}
+
+// doFinalURL makes the request like do, and also returns the URL the request ended up at after any
+// redirects.
+func (c *Client) doFinalURL(req *http.Request) (*html.Node, *url.URL, error) {
 	res, err := c.doRaw(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer res.Body.Close()
 
@@ -172,14 +401,70 @@ func (c *Client) do(req *http.Request) (*html.Node, error) {
 			"content-type": cType,
 			"body":         string(bb),
 		}).Debug("Unexpected content-type")
-		return nil, fmt.Errorf("response content-type %s not expected", cType)
+		return nil, nil, fmt.Errorf("response content-type %s not expected", cType)
+	}
+
+	var body io.Reader = res.Body
+	if c.config.OnHTMLParseError != nil {
+		bb, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+		if warnings := htmlParseWarnings(bb); len(warnings) > 0 {
+			c.config.OnHTMLParseError(req.URL.String(), warnings)
+		}
+		body = bytes.NewReader(bb)
+	}
+
+	root, err := html.Parse(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	finalURL := req.URL
+	if res.Request != nil {
+		finalURL = res.Request.URL
+	}
+
+	// FA redirects to the login page instead of failing if the session is no longer valid. Other pages
+	// can have a login form too, so only the page the request ended up at is checked.
+	if strings.HasPrefix(finalURL.Path, "/login") {
+		lh := &loginPageHandler{}
+		p := subtreeProcessor{
+			tagHandlers: []tagHandler{
+				lh,
+			},
+		}
+		p.processNode(root)
+		if lh.found {
+			log.WithField("url", req.URL).Debug("Redirected to login page")
+			return nil, nil, ErrNotLoggedIn
+		}
 	}
 
-	return html.Parse(res.Body)
+	return root, finalURL, nil
+}
+
+// loginPageHandler detects the login form
+type loginPageHandler struct {
+	found bool
+}
+
+func (*loginPageHandler) matches(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "form" && strings.HasPrefix(findAttribute(n.Attr, "action"), "/login")
+}
+
+func (lh *loginPageHandler) process(*html.Node) bool {
+	lh.found = true
+	return false
 }
 
 func (c *Client) getRaw(url string) ([]byte, error) {
-	req, err := c.newRequest(http.MethodGet, url, nil)
+	return c.getRawWithContext(context.Background(), url)
+}
+
+func (c *Client) getRawWithContext(ctx context.Context, url string) ([]byte, error) {
+	req, err := c.newRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -190,15 +475,56 @@ func (c *Client) getRaw(url string) ([]byte, error) {
 	}
 	defer res.Body.Close()
 
-	bb, err := ioutil.ReadAll(res.Body)
+	body, err := c.decodeContent(res.Header.Get("Content-Encoding"), res.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	bb, err := ioutil.ReadAll(body)
 	if err != nil {
 		return nil, err
 	}
 	return bb, nil
 }
 
+// decodeContent decompresses r according to encoding, the response's Content-Encoding, if
+// DisableCompression is set. Otherwise the transport has already decompressed it.
+func (c *Client) decodeContent(encoding string, r io.Reader) (io.ReadCloser, error) {
+	if !c.config.DisableCompression {
+		return ioutil.NopCloser(r), nil
+	}
+	switch encoding {
+	case "gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		// HTTP deflate is zlib-wrapped
+		return zlib.NewReader(r)
+	default:
+		return ioutil.NopCloser(r), nil
+	}
+}
+
+// head checks that the given URL can be retrieved, without retrieving the body.
+func (c *Client) head(url string) error {
+	req, err := c.newRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.doRaw(req)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
 func (c *Client) get(uri string) (*html.Node, error) {
-	req, err := c.newRequest(http.MethodGet, uri, nil)
+	return c.getWithContext(context.Background(), uri)
+}
+
+func (c *Client) getWithContext(ctx context.Context, uri string) (*html.Node, error) {
+	req, err := c.newRequestWithContext(ctx, http.MethodGet, uri, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -237,9 +563,47 @@ func (c *Client) GetUsername() (string, error) {
 		return "", ErrNotLoggedIn
 	}
 	n := strings.Trim(h.username, "\n ")
+
+	c.usernameLock.Lock()
+	c.username = n
+	c.usernameLock.Unlock()
 	return n, nil
 }
 
+// VerifySession checks that the session is logged in and can access the logged in user's favorites,
+// gallery, and notifications. The returned error describes which check failed.
+func (c *Client) VerifySession() error {
+	name, err := c.GetUsername()
+	if err != nil {
+		return fmt.Errorf("unable to find username: %w", err)
+	}
+
+	for _, check := range []struct {
+		name string
+		uri  string
+	}{
+		{"favorites", "/favorites/" + name + "/"},
+		{"gallery", "/gallery/" + name + "/"},
+		{"notifications", "/msg/others/"},
+	} {
+		if _, err := c.get(check.uri); err != nil {
+			return fmt.Errorf("unable to access %s: %w", check.name, err)
+		}
+	}
+	return nil
+}
+
+// myUsername returns the logged-in username, using the result of a previous GetUsername if available.
+func (c *Client) myUsername() (string, error) {
+	c.usernameLock.Lock()
+	n := c.username
+	c.usernameLock.Unlock()
+	if n != "" {
+		return n, nil
+	}
+	return c.GetUsername()
+}
+
 type myUsernameHandler struct {
 	username string
 }
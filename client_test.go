@@ -0,0 +1,116 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetLoginRedirect(t *testing.T) {
+	const page = `<html><body><form action="/login/?ref=/view/1/" method="post">
+		<input name="name"><input name="pass" type="password"></form></body></html>`
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{"redirected to login", "/login/", ErrNotLoggedIn},
+		// the header of every page has a login form when logged out
+		{"login form on another page", "/view/1/", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				final := req.Clone(req.Context())
+				final.URL.Path = tt.path
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": []string{"text/html; charset=UTF-8"}},
+					Body:       ioutil.NopCloser(strings.NewReader(page)),
+					Request:    final,
+				}, nil
+			}))
+
+			_, err := c.get("/view/1/")
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewEnableHTTP2(t *testing.T) {
+	for _, enable := range []bool{true, false} {
+		c, err := New(Config{
+			EnableHTTP2: enable,
+			RateLimit:   time.Second,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		transport := c.http.Transport.(*http.Transport)
+		if _, ok := transport.TLSNextProto["h2"]; ok != enable {
+			t.Errorf("EnableHTTP2 = %v, h2 configured = %v", enable, ok)
+		}
+		if transport.TLSNextProto == nil {
+			t.Errorf("EnableHTTP2 = %v, TLSNextProto is nil so HTTP/2 may still be negotiated", enable)
+		}
+	}
+}
+
+func TestLimitedBody(t *testing.T) {
+	const max = 4
+	body := "0123456789"
+	lb := &limitedBody{
+		Reader: io.LimitReader(strings.NewReader(body), max+1),
+		Closer: ioutil.NopCloser(nil),
+		max:    max,
+	}
+
+	bb, err := ioutil.ReadAll(lb)
+	if !errors.Is(err, ErrHTTPError) {
+		t.Errorf("err = %v, want ErrHTTPError", err)
+	}
+	if string(bb) != body[:max] {
+		t.Errorf("read %q, want %q", bb, body[:max])
+	}
+	// reading again must keep failing, without a negative count
+	for i := 0; i < 2; i++ {
+		n, err := lb.Read(make([]byte, 8))
+		if n != 0 || !errors.Is(err, ErrHTTPError) {
+			t.Errorf("Read() = %d, %v, want 0, ErrHTTPError", n, err)
+		}
+	}
+}
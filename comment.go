@@ -0,0 +1,271 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"fmt"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+)
+
+// Comment is a comment on a submission or journal.
+type Comment struct {
+	ID int64
+	// SubmissionID or JournalID is set, depending on what the comment was made on.
+	SubmissionID int64
+	JournalID    int64
+	// ParentID is the ID of the comment this is a reply to, or 0 if it is not a reply.
+	ParentID int64
+	User     string
+	Content  string
+	// Children are the replies to this comment, if it was retrieved as part of a comment tree.
+	Children []*Comment
+}
+
+var (
+	commentLinkRegexp   = regexp.MustCompile(`^/(view|journal)/(\d+)/#cid:(\d+)$`)
+	commentAnchorRegexp = regexp.MustCompile(`^#?cid:(\d+)$`)
+)
+
+func (c *Comment) String() string {
+	return fmt.Sprintf("%s: %s (%d)", c.User, c.Content, c.ID)
+}
+
+// GetSubmissionCommentTree retrieves the comments on a submission. The top-level comments are
+// returned, with the replies to each in Children.
+func (c *Client) GetSubmissionCommentTree(submissionID int64) ([]*Comment, error) {
+	log.WithField("id", submissionID).Debug("Retrieving submission comments")
+	flat, err := c.getComments(fmt.Sprintf("/view/%d/", submissionID), &commentContainerHandler{
+		submissionID: submissionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buildCommentTree(flat), nil
+}
+
+// GetJournalCommentTree retrieves the comments on a journal. The top-level comments are returned,
+// with the replies to each in Children.
+func (c *Client) GetJournalCommentTree(journalID int64) ([]*Comment, error) {
+	log.WithField("id", journalID).Debug("Retrieving journal comments")
+	flat, err := c.getComments(fmt.Sprintf("/journal/%d/", journalID), &commentContainerHandler{
+		journalID: journalID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buildCommentTree(flat), nil
+}
+
+func (c *Client) getComments(uri string, ch *commentContainerHandler) ([]*Comment, error) {
+	root, err := c.get(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			ch,
+		},
+	}
+	rp.processNode(root)
+
+	return ch.comments, nil
+}
+
+// buildCommentTree arranges comments into a tree by their ParentID, preserving their order, and
+// returns the top-level comments. Replies to comments which are not present are treated as top-level.
+func buildCommentTree(flat []*Comment) []*Comment {
+	byID := make(map[int64]*Comment, len(flat))
+	for _, c := range flat {
+		c.Children = nil
+		byID[c.ID] = c
+	}
+
+	roots := []*Comment{}
+	for _, c := range flat {
+		if parent, ok := byID[c.ParentID]; ok && c.ParentID != 0 && parent != c {
+			parent.Children = append(parent.Children, c)
+		} else {
+			roots = append(roots, c)
+		}
+	}
+	return roots
+}
+
+// commentContainerHandler extracts each comment on a submission or journal page
+type commentContainerHandler struct {
+	submissionID int64
+	journalID    int64
+	comments     []*Comment
+}
+
+func (*commentContainerHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "div", "comment_container")
+}
+
+func (cch *commentContainerHandler) process(n *html.Node) bool {
+	ah := &commentAnchorHandler{}
+	ul := &userLinkHandler{}
+	ct := &commentTextHandler{}
+	p := subtreeProcessor{
+		tagHandlers: []tagHandler{
+			ah,
+			ul,
+			ct,
+		},
+	}
+	p.processNode(n)
+
+	if ah.id == 0 {
+		return false
+	}
+	c := &Comment{
+		ID:           ah.id,
+		SubmissionID: cch.submissionID,
+		JournalID:    cch.journalID,
+		ParentID:     ah.parentID,
+		Content:      ct.text,
+	}
+	if len(ul.names) > 0 {
+		c.User = ul.names[0]
+	}
+	cch.comments = append(cch.comments, c)
+	return false
+}
+
+// commentAnchorHandler extracts the comment's ID from its anchor, and the ID of the comment it is a
+// reply to from the link to its parent
+type commentAnchorHandler struct {
+	id       int64
+	parentID int64
+}
+
+func (*commentAnchorHandler) matches(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "a" &&
+		(commentAnchorRegexp.MatchString(findAttribute(n.Attr, "id")) || NodeHasClass(n, "comment-parent"))
+}
+
+func (ah *commentAnchorHandler) process(n *html.Node) bool {
+	if m := commentAnchorRegexp.FindStringSubmatch(findAttribute(n.Attr, "id")); m != nil && ah.id == 0 {
+		id, err := parseSubmissionID(m[1])
+		if err != nil {
+			log.WithError(err).Warn("Unable to parse comment ID")
+			return false
+		}
+		ah.id = id
+	} else if NodeHasClass(n, "comment-parent") {
+		if m := commentAnchorRegexp.FindStringSubmatch(findAttribute(n.Attr, "href")); m != nil {
+			id, err := parseSubmissionID(m[1])
+			if err != nil {
+				log.WithError(err).Warn("Unable to parse parent comment ID")
+				return false
+			}
+			ah.parentID = id
+		}
+	}
+	return false
+}
+
+// recentCommentHandler extracts each entry in a list of comments made by a user
+type recentCommentHandler struct {
+	user     string
+	comments []*Comment
+}
+
+func (*recentCommentHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "div", "recent-comment")
+}
+
+func (rch *recentCommentHandler) process(n *html.Node) bool {
+	cl := &commentLinkHandler{}
+	ct := &commentTextHandler{}
+	p := subtreeProcessor{
+		tagHandlers: []tagHandler{
+			cl,
+			ct,
+		},
+	}
+	p.processNode(n)
+
+	if cl.comment == nil {
+		return false
+	}
+	cl.comment.User = rch.user
+	cl.comment.Content = ct.text
+	rch.comments = append(rch.comments, cl.comment)
+	return false
+}
+
+// commentLinkHandler extracts the comment and submission or journal IDs from a link to a comment
+type commentLinkHandler struct {
+	comment *Comment
+}
+
+func (*commentLinkHandler) matches(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "a" && commentLinkRegexp.MatchString(findAttribute(n.Attr, "href"))
+}
+
+func (cl *commentLinkHandler) process(n *html.Node) bool {
+	m := commentLinkRegexp.FindStringSubmatch(findAttribute(n.Attr, "href"))
+	id, err := parseSubmissionID(m[3])
+	if err != nil {
+		log.WithError(err).Warn("Skipping unparseable comment link")
+		return false
+	}
+	parentID, err := parseSubmissionID(m[2])
+	if err != nil {
+		log.WithError(err).Warn("Skipping unparseable comment link")
+		return false
+	}
+	cl.comment = &Comment{
+		ID: id,
+	}
+	if m[1] == "view" {
+		cl.comment.SubmissionID = parentID
+	} else {
+		cl.comment.JournalID = parentID
+	}
+	return false
+}
+
+type commentTextHandler struct {
+	text string
+}
+
+func (*commentTextHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "div", "comment-text")
+}
+
+func (ct *commentTextHandler) process(n *html.Node) bool {
+	ct.text = getText(n)
+	return false
+}
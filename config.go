@@ -34,18 +34,57 @@ import (
 
 // Config is the configuration for the client.
 type Config struct {
-	Cookies []Cookie
-	Proxy   string
+	// AcceptLanguage is sent as the Accept-Language header, so that dates are formatted consistently.
+	// Defaults to "en-US,en;q=0.9".
+	AcceptLanguage string
+	// AdaptiveRateLimit slows down requests while FA is responding slowly. When a request to
+	// furaffinity.net takes more than 1.5x TargetLatency, the interval between requests is doubled;
+	// when one takes less than 0.5x TargetLatency, the interval is reduced back towards RateLimit.
+	AdaptiveRateLimit bool
+	Cookies           []Cookie
+	// DisableCompression disables the transport's transparent decompression of responses. Compressed
+	// downloads are still decompressed explicitly.
+	DisableCompression bool
+	// EnableHTTP2 explicitly configures the transport to use HTTP/2, since a custom proxy can otherwise
+	// silently disable it. When false, HTTP/2 is never negotiated and all requests use HTTP/1.1.
+	// DefaultConfig enables it.
+	EnableHTTP2 bool
+	// MaxResponseBodySize is the largest response body, in bytes, that will be read. Reading past it
+	// fails with ErrHTTPError. 0 means unlimited.
+	MaxResponseBodySize int64
+	// OnHTMLParseError, if set, is called with the URL and a description of each problem when a page's
+	// HTML is malformed. The parser silently corrects malformed HTML, which can lead to handlers not
+	// finding anything.
+	OnHTMLParseError func(url string, warnings []string)
+	Proxy            string
 	// RateLimit is how often requests to furaffinity.net itself are allowed.
 	// Requests to e.g. facdn.net to download images are not affected.
 	RateLimit time.Duration
+	// RequestIDGenerator generates the IDs sent in RequestIDHeader. Defaults to a random UUID.
+	RequestIDGenerator func() string
+	// RequestIDHeader, if set, is the name of a header to send a unique ID in with each request, e.g.
+	// "X-Request-ID".
+	RequestIDHeader string
 	// RequestTimeout is the timeout for a single attempt at the request.
 	RequestTimeout time.Duration
 	RetryDelay     time.Duration
 	RetryLimit     int
+	// TargetLatency is the response time AdaptiveRateLimit aims for.
+	TargetLatency time.Duration
 	// Timeout is the timeout on the entire request, including retries.
 	Timeout   time.Duration
 	UserAgent string
+	// UserAgents are used in rotation, one per request, if UserAgent is not set.
+	UserAgents []string
+}
+
+// DefaultConfig returns a Config with a safe rate limit of one request per second. Cookies and a
+// UserAgent should still be provided.
+func DefaultConfig() Config {
+	return Config{
+		EnableHTTP2: true,
+		RateLimit:   time.Second,
+	}
 }
 
 type Cookie struct {
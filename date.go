@@ -0,0 +1,72 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// faDateFormats are the layouts FA uses to display dates, after ordinal suffixes are removed.
+	faDateFormats = []string{
+		"Jan 2, 2006 03:04 PM",
+		"January 2, 2006 03:04 PM",
+		"Jan 2, 2006 15:04",
+		"January 2, 2006 15:04",
+		"Jan 2, 2006",
+		"January 2, 2006",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+		"January 2",
+		"Jan 2",
+	}
+
+	ordinalSuffixRegexp = regexp.MustCompile(`(\d+)(?:st|nd|rd|th)\b`)
+)
+
+// ParseFADate parses a date as displayed by FA, e.g. "Mar 5th, 2019 01:23 AM". Dates without a year
+// are returned in year 0.
+func ParseFADate(str string) (time.Time, error) {
+	s := strings.TrimSpace(str)
+	s = strings.TrimPrefix(s, "on ")
+	s = ordinalSuffixRegexp.ReplaceAllString(s, "$1")
+	s = strings.Join(strings.Fields(s), " ")
+
+	var err error
+	for _, layout := range faDateFormats {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse date %q: %w", str, err)
+}
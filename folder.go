@@ -0,0 +1,221 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+)
+
+// Folder is a folder in a user's gallery.
+type Folder struct {
+	FolderRef
+	// path is the folder's URI, without a page number
+	path string
+}
+
+const (
+	// folderWorkers is how many folders are retrieved concurrently.
+	folderWorkers = 4
+)
+
+// GetFolders retrieves the folders in the user's gallery.
+func (u *User) GetFolders() ([]*Folder, error) {
+	return u.getFolders(context.Background())
+}
+
+// GetFolderSubmissions retrieves the specified page of the submissions in one of the user's gallery
+// folders. Page numbering starts at 1.
+func (u *User) GetFolderSubmissions(folder *Folder, page uint) ([]*Submission, error) {
+	gp, err := u.getFolderPage(context.Background(), folder, page)
+	if err != nil {
+		return nil, err
+	}
+	return gp.Submissions, nil
+}
+
+// GetGalleryWithFolders retrieves every submission in the user's gallery, keyed by the name of the
+// folder it is in. Submissions that are not in any folder are under the empty string. Folders are
+// retrieved concurrently; cancelling ctx stops all of them.
+func (u *User) GetGalleryWithFolders(ctx context.Context) (map[string][]*Submission, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	folders, err := u.getFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type folderResult struct {
+		name string
+		subs []*Submission
+		err  error
+	}
+	work := make(chan *Folder)
+	results := make(chan folderResult, len(folders))
+	var wg sync.WaitGroup
+	for i := 0; i < folderWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range work {
+				subs, err := u.getAllFolderSubmissions(ctx, f)
+				if err != nil {
+					// no point in continuing with the other folders
+					cancel()
+				}
+				results <- folderResult{
+					name: f.Name,
+					subs: subs,
+					err:  err,
+				}
+			}
+		}()
+	}
+
+	for _, f := range folders {
+		work <- f
+	}
+	close(work)
+	wg.Wait()
+	close(results)
+
+	byFolder := make(map[string][]*Submission)
+	inFolder := make(map[int64]bool)
+	for r := range results {
+		if r.err != nil {
+			if err == nil {
+				err = r.err
+			}
+			continue
+		}
+		byFolder[r.name] = append(byFolder[r.name], r.subs...)
+		for _, sub := range r.subs {
+			inFolder[sub.ID] = true
+		}
+	}
+	if err != nil {
+		return byFolder, err
+	}
+
+	all, err := u.GetGalleryWithProgress(ctx, SubmissionTypeGallery, nil)
+	if err != nil {
+		return byFolder, err
+	}
+	for _, sub := range all {
+		if !inFolder[sub.ID] {
+			byFolder[""] = append(byFolder[""], sub)
+		}
+	}
+	return byFolder, nil
+}
+
+// GetGalleryFolderCount retrieves the number of folders in the user's gallery.
+func (u *User) GetGalleryFolderCount() (int, error) {
+	log.WithField("user", u).Debug("Retrieving folder count")
+	root, err := u.c.get(fmt.Sprintf("/%s/%s/", SubmissionTypeGallery.URI(), u.name))
+	if err != nil {
+		return 0, err
+	}
+
+	fc := &folderCountHandler{
+		ids: make(map[string]bool),
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			fc,
+		},
+	}
+	rp.processNode(root)
+
+	return len(fc.ids), nil
+}
+
+func (u *User) getFolders(ctx context.Context) ([]*Folder, error) {
+	log.WithField("user", u).Debug("Retrieving folders")
+	root, err := u.c.getWithContext(ctx, fmt.Sprintf("/%s/%s/", SubmissionTypeGallery.URI(), u.name))
+	if err != nil {
+		return nil, err
+	}
+
+	fl := &folderLinkHandler{}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			fl,
+		},
+	}
+	rp.processNode(root)
+
+	return fl.folders, nil
+}
+
+func (u *User) getAllFolderSubmissions(ctx context.Context, folder *Folder) ([]*Submission, error) {
+	var all []*Submission
+	for page := uint(1); ; page++ {
+		gp, err := u.getFolderPage(ctx, folder, page)
+		if err != nil {
+			return all, err
+		}
+		if len(gp.Submissions) == 0 {
+			return all, nil
+		}
+		all = append(all, gp.Submissions...)
+	}
+}
+
+func (u *User) getFolderPage(ctx context.Context, folder *Folder, page uint) (*GalleryPage, error) {
+	if page == 0 {
+		page = 1
+	}
+	log.WithField("user", u).WithField("folder", folder.Name).WithField("page", page).Debug("Retrieving folder submissions")
+
+	path := folder.path
+	if path == "" {
+		path = fmt.Sprintf("/%s/%s/folder/%d/", SubmissionTypeGallery.URI(), u.name, folder.ID)
+	}
+	return u.getGalleryPageURI(ctx, path, page)
+}
+
+// folderCountHandler collects the distinct folder IDs linked to
+type folderCountHandler struct {
+	ids map[string]bool
+}
+
+func (*folderCountHandler) matches(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "a" && folderRegexp.MatchString(findAttribute(n.Attr, "href"))
+}
+
+func (fc *folderCountHandler) process(n *html.Node) bool {
+	fc.ids[folderRegexp.FindStringSubmatch(findAttribute(n.Attr, "href"))[1]] = true
+	return false
+}
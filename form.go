@@ -0,0 +1,164 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// formHandler finds a form by its ID, or by its action if id is empty, and extracts the values it
+// would submit
+type formHandler struct {
+	id     string
+	action string
+	found  bool
+	values url.Values
+	// options are the values of every option of each select in the form
+	options map[string][]string
+}
+
+func (fh *formHandler) matches(n *html.Node) bool {
+	if fh.id != "" {
+		return checkNodeTagNameAndID(n, "form", fh.id)
+	}
+	return checkNodeTagNameAndAttribute(n, "form", "action", fh.action)
+}
+
+func (fh *formHandler) process(n *html.Node) bool {
+	ffh := &formFieldHandler{
+		values:  url.Values{},
+		options: map[string][]string{},
+	}
+	p := subtreeProcessor{
+		tagHandlers: []tagHandler{
+			ffh,
+		},
+	}
+	p.processNode(n)
+	fh.found = true
+	fh.action = findAttribute(n.Attr, "action")
+	fh.values = ffh.values
+	fh.options = ffh.options
+	return false
+}
+
+// formFieldHandler extracts the current value of each named field in a form
+type formFieldHandler struct {
+	values  url.Values
+	options map[string][]string
+}
+
+func (*formFieldHandler) matches(n *html.Node) bool {
+	if n.Type != html.ElementNode || findAttribute(n.Attr, "name") == "" {
+		return false
+	}
+	return n.Data == "input" || n.Data == "select" || n.Data == "textarea"
+}
+
+func (ffh *formFieldHandler) process(n *html.Node) bool {
+	name := findAttribute(n.Attr, "name")
+	switch n.Data {
+	case "input":
+		switch strings.ToLower(findAttribute(n.Attr, "type")) {
+		case "button", "file", "image", "reset":
+		case "checkbox", "radio":
+			if hasAttribute(n.Attr, "checked") {
+				ffh.values.Add(name, findAttribute(n.Attr, "value"))
+			}
+		default:
+			ffh.values.Add(name, findAttribute(n.Attr, "value"))
+		}
+	case "select":
+		var first, selected *html.Node
+		for o := n.FirstChild; o != nil; o = o.NextSibling {
+			if o.Type != html.ElementNode || o.Data != "option" {
+				continue
+			}
+			ffh.options[name] = append(ffh.options[name], optionValue(o))
+			if first == nil {
+				first = o
+			}
+			if selected == nil && hasAttribute(o.Attr, "selected") {
+				selected = o
+			}
+		}
+		if selected == nil {
+			selected = first
+		}
+		if selected != nil {
+			ffh.values.Add(name, optionValue(selected))
+		}
+	case "textarea":
+		if n.FirstChild != nil {
+			ffh.values.Add(name, n.FirstChild.Data)
+		} else {
+			ffh.values.Add(name, "")
+		}
+	}
+	return false
+}
+
+// selectOptionsHandler extracts the value of every option of the named select
+type selectOptionsHandler struct {
+	name   string
+	values []string
+}
+
+func (soh *selectOptionsHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndAttribute(n, "select", "name", soh.name)
+}
+
+func (soh *selectOptionsHandler) process(n *html.Node) bool {
+	for o := n.FirstChild; o != nil; o = o.NextSibling {
+		if o.Type == html.ElementNode && o.Data == "option" {
+			soh.values = append(soh.values, optionValue(o))
+		}
+	}
+	return false
+}
+
+func hasAttribute(attrs []html.Attribute, name string) bool {
+	for _, a := range attrs {
+		if a.Key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// optionValue returns the value an option would submit, which is its text if it has no value attribute.
+func optionValue(n *html.Node) string {
+	if hasAttribute(n.Attr, "value") {
+		return findAttribute(n.Attr, "value")
+	}
+	return getText(n)
+}
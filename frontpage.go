@@ -0,0 +1,116 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// FrontPage is the collection of submissions shown on the FA home page.
+type FrontPage struct {
+	Featured    []*Submission
+	RecentArt   []*Submission
+	RecentFlash []*Submission
+	RecentMusic []*Submission
+	RecentStory []*Submission
+}
+
+// GetFrontPage retrieves the submissions currently shown in each section of the FA home page.
+func (c *Client) GetFrontPage() (*FrontPage, error) {
+	log.Debug("Retrieving front page")
+	root, err := c.get("/")
+	if err != nil {
+		return nil, err
+	}
+
+	featured := &submissionSectionHandler{
+		c:          c,
+		sectionIDs: []string{"featured-previews"},
+	}
+	art := &submissionSectionHandler{
+		c:          c,
+		sectionIDs: []string{"art-previews"},
+	}
+	flash := &submissionSectionHandler{
+		c:          c,
+		sectionIDs: []string{"flash-previews"},
+	}
+	music := &submissionSectionHandler{
+		c:          c,
+		sectionIDs: []string{"music-previews"},
+	}
+	story := &submissionSectionHandler{
+		c:          c,
+		sectionIDs: []string{"story-previews"},
+	}
+	scripts := &scriptHandler{
+		regexp: submissionDataRegexp,
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			featured,
+			art,
+			flash,
+			music,
+			story,
+			scripts,
+		},
+	}
+	rp.processNode(root)
+	for _, h := range []*submissionSectionHandler{featured, art, flash, music, story} {
+		if h.err != nil {
+			return nil, h.err
+		}
+	}
+
+	return &FrontPage{
+		Featured:    attachSubmissionData(featured.subs, scripts.data),
+		RecentArt:   attachSubmissionData(art.subs, scripts.data),
+		RecentFlash: attachSubmissionData(flash.subs, scripts.data),
+		RecentMusic: attachSubmissionData(music.subs, scripts.data),
+		RecentStory: attachSubmissionData(story.subs, scripts.data),
+	}, nil
+}
+
+// GetFeaturedArtist checks whether any of the user's submissions are currently featured on the FA home
+// page.
+func (u *User) GetFeaturedArtist() (bool, error) {
+	fp, err := u.c.GetFrontPage()
+	if err != nil {
+		return false, err
+	}
+	for _, sub := range fp.Featured {
+		if strings.EqualFold(sub.User, u.name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -32,20 +32,44 @@ import (
 	"fmt"
 	"strings"
 
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/html"
 )
 
 // Journal is a journal entry.
 type Journal struct {
-	c       *Client
-	ID      int64
-	Title   string
-	User    string
-	content *string
+	c     *Client
+	ID    int64
+	Title string
+	User  string
+	// PreviousJournalID and NextJournalID are the IDs of the user's adjacent journals, or 0 if there
+	// is none. They are populated by Content.
+	PreviousJournalID int64
+	NextJournalID     int64
+	content           *string
+}
+
+var (
+	// announcementAccounts are the FA staff accounts that post site announcements.
+	announcementAccounts = []string{"dragoneer", "fender"}
+)
+
+// GetSiteAnnouncements retrieves the most recent journals posted by the FA staff accounts that make
+// site announcements.
+func (c *Client) GetSiteAnnouncements() ([]*Journal, error) {
+	var journs []*Journal
+	for _, name := range announcementAccounts {
+		js, err := c.NewUser(name).GetJournals(1)
+		if err != nil {
+			return journs, err
+		}
+		journs = append(journs, js...)
+	}
+	return journs, nil
 }
 
 func (j *Journal) String() string {
-	return fmt.Sprintf("%s (%s)", j.Title, j.ID)
+	return fmt.Sprintf("%s (%d)", j.Title, j.ID)
 }
 
 func (j *Journal) URL() string {
@@ -64,14 +88,19 @@ func (j *Journal) Content() (string, error) {
 
 	jch := &journalContentHandler{}
 	jdh := &journalDateHandler{}
+	jnh := &journalNavHandler{}
 	rp := &subtreeProcessor{
 		tagHandlers: []tagHandler{
 			jch,
 			jdh,
+			jnh,
 		},
 	}
 	rp.processNode(root)
 
+	j.PreviousJournalID = jnh.prev
+	j.NextJournalID = jnh.next
+
 	s := jdh.text + "\n\n" + jch.text
 	j.content = &s
 	return s, nil
@@ -106,3 +135,34 @@ func (dh *journalDateHandler) process(n *html.Node) bool {
 	dh.text = n.FirstChild.Data
 	return true
 }
+
+// journalNavHandler extracts the IDs from the links to the previous and next journals
+type journalNavHandler struct {
+	prev int64
+	next int64
+}
+
+func (*journalNavHandler) matches(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "a" && journalRegexp.MatchString(findAttribute(n.Attr, "href"))
+}
+
+func (jnh *journalNavHandler) process(n *html.Node) bool {
+	text := strings.ToLower(getText(n))
+	var dest *int64
+	switch {
+	case strings.Contains(text, "prev") || strings.Contains(text, "older"):
+		dest = &jnh.prev
+	case strings.Contains(text, "next") || strings.Contains(text, "newer"):
+		dest = &jnh.next
+	default:
+		return false
+	}
+
+	id, err := parseSubmissionID(journalRegexp.FindStringSubmatch(findAttribute(n.Attr, "href"))[1])
+	if err != nil {
+		log.WithError(err).Warn("Skipping unparseable journal link")
+		return false
+	}
+	*dest = id
+	return false
+}
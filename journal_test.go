@@ -0,0 +1,64 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"testing"
+)
+
+func TestJournalHandlers(t *testing.T) {
+	jch := &journalContentHandler{}
+	jdh := &journalDateHandler{}
+	jnh := &journalNavHandler{}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			jch,
+			jdh,
+			jnh,
+		},
+	}
+	rp.processNode(loadFixture(t, "journal.html"))
+
+	if jch.text != "This is the body of the journal." {
+		t.Errorf("content = %q", jch.text)
+	}
+	if jdh.text != "Jan 3, 2020 10:00 AM" {
+		t.Errorf("date = %q", jdh.text)
+	}
+	if jnh.prev != 200 || jnh.next != 202 {
+		t.Errorf("prev = %d, next = %d, want 200, 202", jnh.prev, jnh.next)
+	}
+}
+
+func TestJournalURL(t *testing.T) {
+	j := &Journal{ID: 201}
+	if got, want := j.URL(), "https://www.furaffinity.net/journal/201/"; got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+// GetMyJournals retrieves the specified page of the logged-in user's journal. Page numbering starts at 1.
+// Returns ErrNotLoggedIn if not logged in.
+func (c *Client) GetMyJournals(page uint) ([]*Journal, error) {
+	name, err := c.myUsername()
+	if err != nil {
+		return nil, err
+	}
+	return c.NewUser(name).GetJournals(page)
+}
+
+// GetMyWatching retrieves the specified page of the users the logged-in user is watching. Page
+// numbering starts at 1. Returns ErrNotLoggedIn if not logged in.
+func (c *Client) GetMyWatching(page uint) ([]*User, error) {
+	name, err := c.myUsername()
+	if err != nil {
+		return nil, err
+	}
+	return c.NewUser(name).GetWatching(page)
+}
+
+// GetMyWatchers retrieves the specified page of the users watching the logged-in user. Page numbering
+// starts at 1. Returns ErrNotLoggedIn if not logged in.
+func (c *Client) GetMyWatchers(page uint) ([]*User, error) {
+	name, err := c.myUsername()
+	if err != nil {
+		return nil, err
+	}
+	return c.NewUser(name).GetWatchers(page)
+}
+
+// GetMyFavorites retrieves the specified page of the logged-in user's favorites. Page numbering starts
+// at 1. Returns ErrNotLoggedIn if not logged in.
+func (c *Client) GetMyFavorites(page uint) ([]*Submission, error) {
+	name, err := c.myUsername()
+	if err != nil {
+		return nil, err
+	}
+	return c.NewUser(name).GetFavorites(page)
+}
+
+// GetMyGallery retrieves the specified page of the logged-in user's gallery. Page numbering starts at 1.
+// Returns ErrNotLoggedIn if not logged in.
+func (c *Client) GetMyGallery(page uint) ([]*Submission, error) {
+	name, err := c.myUsername()
+	if err != nil {
+		return nil, err
+	}
+	return c.NewUser(name).GetGallery(SubmissionTypeGallery, page)
+}
+
+// GetMyScraps retrieves the specified page of the logged-in user's scraps. Page numbering starts at 1.
+// Returns ErrNotLoggedIn if not logged in.
+func (c *Client) GetMyScraps(page uint) ([]*Submission, error) {
+	name, err := c.myUsername()
+	if err != nil {
+		return nil, err
+	}
+	return c.NewUser(name).GetGallery(SubmissionTypeScraps, page)
+}
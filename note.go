@@ -0,0 +1,374 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+)
+
+// Note is a private message.
+type Note struct {
+	c       *Client
+	ID      int64
+	Subject string
+	From    string
+	Date    string
+	Unread  bool
+	// Content is only populated for notes retrieved individually.
+	Content string
+	// Thread is the replies to this note, oldest first, if it was retrieved by GetNoteThread.
+	Thread []*Note
+	// replyTo is the ID of the note this is a reply to, if known
+	replyTo int64
+	// threadID is the ID of the conversation FA has grouped this note into, if known
+	threadID int64
+}
+
+// NoteFolder is a folder in the note inbox.
+type NoteFolder string
+
+// NoteFolder values
+const (
+	NoteInbox NoteFolder = "inbox"
+	NoteSent  NoteFolder = "sent"
+	NoteTrash NoteFolder = "trash"
+)
+
+var (
+	noteRegexp       = regexp.MustCompile(`^/viewmessage/(\d+)/$`)
+	noteThreadRegexp = regexp.MustCompile(`^/controls/switchbox/thread/(\d+)/$`)
+)
+
+func (n *Note) String() string {
+	return fmt.Sprintf("%s from %s (%d)", n.Subject, n.From, n.ID)
+}
+
+// URI returns the path component used by FA for the folder, or an error if it is not one of the
+// NoteFolder values.
+func (nf NoteFolder) URI() (string, error) {
+	switch nf {
+	case NoteInbox:
+		return "inbox", nil
+	case NoteSent:
+		return "outbox", nil
+	case NoteTrash:
+		return "trash", nil
+	default:
+		return "", fmt.Errorf("unknown note folder %q", string(nf))
+	}
+}
+
+// GetNotes retrieves the specified page of notes in the given folder. Page numbering starts at 1.
+func (c *Client) GetNotes(folder NoteFolder, page uint) ([]*Note, error) {
+	if page == 0 {
+		page = 1
+	}
+	log.WithField("folder", folder).WithField("page", page).Debug("Retrieving notes")
+
+	var notes []*Note
+	uri, err := folder.URI()
+	if err != nil {
+		return notes, err
+	}
+	root, err := c.get(fmt.Sprintf("/controls/switchbox/%s/%d/", uri, page))
+	if err != nil {
+		return notes, err
+	}
+
+	nh := &noteHandler{
+		c: c,
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			nh,
+		},
+	}
+	rp.processNode(root)
+
+	return nh.notes, nil
+}
+
+// GetNoteThread retrieves the whole conversation which the note with noteID is part of. The notes are
+// returned oldest first, and the first note's Thread contains the replies. Each note in the thread is
+// retrieved individually so that its Content is populated. If FA has not grouped the note into a
+// thread, the chain of replies is followed back from noteID instead, so replies made after it are not
+// included.
+func (c *Client) GetNoteThread(noteID int64) ([]*Note, error) {
+	note, err := c.GetNote(noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	var thread []*Note
+	if note.threadID != 0 {
+		thread, err = c.getThreadNotes(note)
+	} else {
+		thread, err = c.getReplyChain(note)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(thread) > 0 {
+		thread[0].Thread = thread[1:]
+	}
+	return thread, nil
+}
+
+// getThreadNotes retrieves every note in the thread that note is part of, oldest first.
+func (c *Client) getThreadNotes(note *Note) ([]*Note, error) {
+	log.WithField("thread", note.threadID).Debug("Retrieving note thread")
+	root, err := c.get(fmt.Sprintf("/controls/switchbox/thread/%d/", note.threadID))
+	if err != nil {
+		return nil, err
+	}
+
+	nh := &noteHandler{
+		c: c,
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			nh,
+		},
+	}
+	rp.processNode(root)
+
+	// FA lists the thread newest first, and IDs increase over time
+	sort.Slice(nh.notes, func(i, j int) bool {
+		return nh.notes[i].ID < nh.notes[j].ID
+	})
+	thread := make([]*Note, 0, len(nh.notes))
+	for _, n := range nh.notes {
+		if n.ID == note.ID {
+			thread = append(thread, note)
+			continue
+		}
+		full, err := c.GetNote(n.ID)
+		if err != nil {
+			return nil, err
+		}
+		thread = append(thread, full)
+	}
+	return thread, nil
+}
+
+// getReplyChain follows the chain of replies back from note to the first note, and returns the notes
+// oldest first.
+func (c *Client) getReplyChain(note *Note) ([]*Note, error) {
+	thread := []*Note{note}
+	seen := map[int64]bool{note.ID: true}
+	for id := note.replyTo; id != 0 && !seen[id]; {
+		seen[id] = true
+		n, err := c.GetNote(id)
+		if err != nil {
+			return nil, err
+		}
+		thread = append([]*Note{n}, thread...)
+		id = n.replyTo
+	}
+	return thread, nil
+}
+
+// GetNote retrieves a single note, including its content.
+func (c *Client) GetNote(id int64) (*Note, error) {
+	log.WithField("id", id).Debug("Retrieving note")
+	root, err := c.get(fmt.Sprintf("/viewmessage/%d/", id))
+	if err != nil {
+		return nil, err
+	}
+
+	nvh := &noteViewHandler{}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			nvh,
+		},
+	}
+	rp.processNode(root)
+
+	if !nvh.found {
+		return nil, fmt.Errorf("note %d not found", id)
+	}
+	nvh.note.c = c
+	nvh.note.ID = id
+	return &nvh.note, nil
+}
+
+// noteHandler finds and extracts each note row in the notes list
+type noteHandler struct {
+	c     *Client
+	notes []*Note
+}
+
+func (*noteHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "tr", "note")
+}
+
+func (nh *noteHandler) process(n *html.Node) bool {
+	nl := &noteLinkHandler{}
+	nd := &journalDateHandler{}
+	p := subtreeProcessor{
+		tagHandlers: []tagHandler{
+			nl,
+			nd,
+		},
+	}
+	p.processNode(n)
+
+	if nl.id == 0 {
+		return false
+	}
+	nh.notes = append(nh.notes, &Note{
+		c:       nh.c,
+		ID:      nl.id,
+		Subject: nl.subject,
+		From:    nl.from,
+		Date:    nd.text,
+		Unread:  NodeHasClass(n, "unread"),
+	})
+	return false
+}
+
+type noteLinkHandler struct {
+	id      int64
+	subject string
+	from    string
+}
+
+func (*noteLinkHandler) matches(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "a"
+}
+
+func (nlh *noteLinkHandler) process(n *html.Node) bool {
+	href := findAttribute(n.Attr, "href")
+	if m := noteRegexp.FindStringSubmatch(href); m != nil {
+		id, err := parseSubmissionID(m[1])
+		if err != nil {
+			log.WithError(err).Warn("Skipping unparseable note link")
+			return false
+		}
+		nlh.id = id
+		nlh.subject = getText(n)
+	} else if strings.HasPrefix(href, "/user/") {
+		nlh.from = getText(n)
+	}
+	return false
+}
+
+// noteViewHandler extracts a note from its page
+type noteViewHandler struct {
+	found bool
+	note  Note
+}
+
+func (*noteViewHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndID(n, "div", "message")
+}
+
+func (nvh *noteViewHandler) process(n *html.Node) bool {
+	subject := &classTextHandler{
+		class: "note-subject",
+	}
+	content := &classTextHandler{
+		class: "note-content",
+	}
+	reply := &noteReplyToHandler{}
+	thread := &noteThreadHandler{}
+	from := &userLinkHandler{}
+	date := &journalDateHandler{}
+	p := subtreeProcessor{
+		tagHandlers: []tagHandler{
+			subject,
+			content,
+			reply,
+			thread,
+			from,
+			date,
+		},
+	}
+	p.processNode(n)
+
+	nvh.found = true
+	nvh.note.Subject = subject.text
+	nvh.note.Content = content.text
+	nvh.note.Date = date.text
+	nvh.note.replyTo = reply.id
+	nvh.note.threadID = thread.id
+	if len(from.names) > 0 {
+		nvh.note.From = from.names[0]
+	}
+	return false
+}
+
+// noteReplyToHandler extracts the ID of the note this note is in reply to
+type noteReplyToHandler struct {
+	id int64
+}
+
+func (*noteReplyToHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "a", "reply-to")
+}
+
+func (nrh *noteReplyToHandler) process(n *html.Node) bool {
+	if m := noteRegexp.FindStringSubmatch(findAttribute(n.Attr, "href")); m != nil {
+		id, err := parseSubmissionID(m[1])
+		if err != nil {
+			log.WithError(err).Warn("Unable to parse note reply link")
+			return false
+		}
+		nrh.id = id
+	}
+	return false
+}
+
+// noteThreadHandler extracts the ID of the thread the note is part of
+type noteThreadHandler struct {
+	id int64
+}
+
+func (*noteThreadHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "a", "note-thread")
+}
+
+func (nth *noteThreadHandler) process(n *html.Node) bool {
+	if m := noteThreadRegexp.FindStringSubmatch(findAttribute(n.Attr, "href")); m != nil {
+		id, err := parseSubmissionID(m[1])
+		if err != nil {
+			log.WithError(err).Warn("Unable to parse note thread link")
+			return false
+		}
+		nth.id = id
+	}
+	return false
+}
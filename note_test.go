@@ -0,0 +1,119 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// notePage renders the view page of a note, with optional links to the note it replies to and its
+// thread.
+func notePage(id, replyTo, thread int64) string {
+	var links string
+	if replyTo != 0 {
+		links += fmt.Sprintf(`<a class="reply-to" href="/viewmessage/%d/">In reply to</a>`, replyTo)
+	}
+	if thread != 0 {
+		links += fmt.Sprintf(`<a class="note-thread" href="/controls/switchbox/thread/%d/">View thread</a>`, thread)
+	}
+	return fmt.Sprintf(`<html><body><div id="message">
+		<div class="note-subject">Subject %d</div>
+		<a href="/user/sender/">sender</a>
+		%s
+		<div class="note-content">Content %d</div>
+	</div></body></html>`, id, links, id)
+}
+
+func newNoteTestClient(t *testing.T, pages map[string]string) *Client {
+	t.Helper()
+	return newTestClient(t, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		page, ok := pages[req.URL.Path]
+		if !ok {
+			return nil, fmt.Errorf("unexpected request for %s", req.URL)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"text/html; charset=UTF-8"}},
+			Body:       ioutil.NopCloser(strings.NewReader(page)),
+			Request:    req,
+		}, nil
+	}))
+}
+
+func TestGetNoteThread(t *testing.T) {
+	c := newNoteTestClient(t, map[string]string{
+		"/viewmessage/1/": notePage(1, 0, 7),
+		"/viewmessage/2/": notePage(2, 1, 7),
+		"/viewmessage/3/": notePage(3, 2, 7),
+		"/controls/switchbox/thread/7/": `<html><body><table>
+			<tr class="note"><td><a href="/viewmessage/3/">Re: Re: Subject</a></td></tr>
+			<tr class="note"><td><a href="/viewmessage/2/">Re: Subject</a></td></tr>
+			<tr class="note"><td><a href="/viewmessage/1/">Subject</a></td></tr>
+		</table></body></html>`,
+	})
+
+	// the thread includes the reply made after the requested note
+	thread, err := c.GetNoteThread(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(thread) != 3 {
+		t.Fatalf("len(thread) = %d, want 3", len(thread))
+	}
+	for i, n := range thread {
+		if n.ID != int64(i+1) || n.Content != fmt.Sprintf("Content %d", i+1) {
+			t.Errorf("thread[%d] = %d %q, want %d %q", i, n.ID, n.Content, i+1, fmt.Sprintf("Content %d", i+1))
+		}
+	}
+	if len(thread[0].Thread) != 2 || thread[0].Thread[1].ID != 3 {
+		t.Errorf("thread[0].Thread = %v, want notes 2 and 3", thread[0].Thread)
+	}
+}
+
+func TestGetNoteThreadReplyChain(t *testing.T) {
+	c := newNoteTestClient(t, map[string]string{
+		"/viewmessage/1/": notePage(1, 0, 0),
+		"/viewmessage/2/": notePage(2, 1, 0),
+	})
+
+	thread, err := c.GetNoteThread(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(thread) != 2 || thread[0].ID != 1 || thread[1].ID != 2 {
+		t.Fatalf("thread = %v, want notes 1 and 2", thread)
+	}
+	if len(thread[0].Thread) != 1 || thread[0].Thread[0] != thread[1] {
+		t.Errorf("thread[0].Thread = %v, want note 2", thread[0].Thread)
+	}
+}
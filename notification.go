@@ -0,0 +1,276 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+)
+
+// Notification is an entry in the notification inbox.
+type Notification struct {
+	ID         int64
+	User       string
+	OccurredAt time.Time
+}
+
+// WatchNotification is a notification that a user started watching the logged-in user.
+type WatchNotification struct {
+	Notification
+}
+
+// CommentNotification is a notification of a comment on a submission or journal. ID is the comment's ID.
+type CommentNotification struct {
+	Notification
+	// SubmissionID or JournalID is set, depending on what the comment was made on.
+	SubmissionID int64
+	JournalID    int64
+}
+
+// Notifications are the entries in the notification inbox, other than new submissions.
+type Notifications struct {
+	Watches  []*WatchNotification
+	Comments []*CommentNotification
+}
+
+// GetNotifications retrieves the watch and comment notifications from the notification inbox.
+func (c *Client) GetNotifications() (*Notifications, error) {
+	log.Debug("Retrieving notifications")
+	root, err := c.get("/msg/others/")
+	if err != nil {
+		return nil, err
+	}
+
+	watches := &notificationSectionHandler{
+		sectionIDs: []string{"messages-watches"},
+	}
+	comments := &notificationSectionHandler{
+		sectionIDs: []string{"messages-comments-submission", "messages-comments-journal"},
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			watches,
+			comments,
+		},
+	}
+	rp.processNode(root)
+
+	ns := &Notifications{}
+	for _, e := range watches.entries {
+		ns.Watches = append(ns.Watches, &WatchNotification{
+			Notification: e.Notification,
+		})
+	}
+	for _, e := range comments.entries {
+		cn := &CommentNotification{
+			Notification: e.Notification,
+		}
+		if e.comment != nil {
+			cn.ID = e.comment.ID
+			cn.SubmissionID = e.comment.SubmissionID
+			cn.JournalID = e.comment.JournalID
+		}
+		ns.Comments = append(ns.Comments, cn)
+	}
+	return ns, nil
+}
+
+// GetWatchlistNewSubmissions retrieves the new submissions from watched users in the submission
+// inbox.
+func (c *Client) GetWatchlistNewSubmissions() ([]*Submission, error) {
+	log.Debug("Retrieving new submissions")
+	root, err := c.get("/msg/submissions/")
+	if err != nil {
+		return nil, err
+	}
+
+	// the inbox has a section for each day
+	submissions := &submissionSectionHandler{
+		c:            c,
+		sectionClass: "messagecenter",
+	}
+	scripts := &scriptHandler{
+		regexp: galleryDataRegexp,
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			submissions,
+			scripts,
+		},
+	}
+	rp.processNode(root)
+	if submissions.err != nil {
+		return nil, submissions.err
+	}
+
+	return attachSubmissionData(submissions.subs, scripts.data), nil
+}
+
+// FilterNotificationsSince returns the notifications that occurred after since.
+func FilterNotificationsSince(notifications []*Notification, since time.Time) []*Notification {
+	var filtered []*Notification
+	for _, n := range notifications {
+		if n.OccurredAt.After(since) {
+			filtered = append(filtered, n)
+		}
+	}
+	return filtered
+}
+
+// FilterWatchNotificationsSince returns the watch notifications that occurred after since.
+func FilterWatchNotificationsSince(notifications []*WatchNotification, since time.Time) []*WatchNotification {
+	var filtered []*WatchNotification
+	for _, n := range notifications {
+		if n.OccurredAt.After(since) {
+			filtered = append(filtered, n)
+		}
+	}
+	return filtered
+}
+
+// FilterCommentNotificationsSince returns the comment notifications that occurred after since.
+func FilterCommentNotificationsSince(notifications []*CommentNotification, since time.Time) []*CommentNotification {
+	var filtered []*CommentNotification
+	for _, n := range notifications {
+		if n.OccurredAt.After(since) {
+			filtered = append(filtered, n)
+		}
+	}
+	return filtered
+}
+
+type notificationEntry struct {
+	Notification
+	comment *Comment
+}
+
+// notificationSectionHandler finds the notification sections with any of sectionIDs and extracts each
+// entry
+type notificationSectionHandler struct {
+	sectionIDs []string
+	entries    []*notificationEntry
+}
+
+func (nsh *notificationSectionHandler) matches(n *html.Node) bool {
+	return checkNodeSectionID(n, nsh.sectionIDs)
+}
+
+func (nsh *notificationSectionHandler) process(n *html.Node) bool {
+	neh := &notificationEntryHandler{}
+	p := subtreeProcessor{
+		tagHandlers: []tagHandler{
+			neh,
+		},
+	}
+	p.processNode(n)
+	nsh.entries = append(nsh.entries, neh.entries...)
+	return false
+}
+
+// notificationEntryHandler extracts each notification in a list
+type notificationEntryHandler struct {
+	entries []*notificationEntry
+}
+
+func (*notificationEntryHandler) matches(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "li"
+}
+
+func (neh *notificationEntryHandler) process(n *html.Node) bool {
+	ch := &notificationCheckboxHandler{}
+	cl := &commentLinkHandler{}
+	ul := &userLinkHandler{}
+	dh := &popupDateHandler{}
+	p := subtreeProcessor{
+		tagHandlers: []tagHandler{
+			ch,
+			cl,
+			ul,
+			dh,
+		},
+	}
+	p.processNode(n)
+
+	if ch.id == 0 {
+		return false
+	}
+	e := &notificationEntry{
+		Notification: Notification{
+			ID:         ch.id,
+			OccurredAt: dh.date,
+		},
+		comment: cl.comment,
+	}
+	if len(ul.names) > 0 {
+		e.User = ul.names[0]
+	}
+	neh.entries = append(neh.entries, e)
+	return false
+}
+
+// notificationCheckboxHandler extracts the notification's ID from its selection checkbox
+type notificationCheckboxHandler struct {
+	id int64
+}
+
+func (*notificationCheckboxHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndAttribute(n, "input", "type", "checkbox")
+}
+
+func (ch *notificationCheckboxHandler) process(n *html.Node) bool {
+	id, err := parseSubmissionID(findAttribute(n.Attr, "value"))
+	if err != nil {
+		log.WithError(err).Warn("Skipping notification with unparseable ID")
+		return false
+	}
+	ch.id = id
+	return false
+}
+
+// popupDateHandler parses the date from a popup_date span, which has the full date in either its
+// title or its text
+type popupDateHandler struct {
+	date time.Time
+}
+
+func (*popupDateHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "span", "popup_date")
+}
+
+func (dh *popupDateHandler) process(n *html.Node) bool {
+	for _, s := range []string{findAttribute(n.Attr, "title"), getText(n)} {
+		if t, err := ParseFADate(s); err == nil {
+			dh.date = t
+			break
+		}
+	}
+	return false
+}
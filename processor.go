@@ -29,8 +29,14 @@
 package faapi
 
 import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/html"
 )
 
@@ -58,6 +64,180 @@ func (rp *subtreeProcessor) processNode(n *html.Node) {
 	}
 }
 
+// labeledValueHandler finds an element whose text is one of labels, e.g. <b>Species:</b>, and reads
+// the value that follows it
+type labeledValueHandler struct {
+	labels []string
+	value  string
+}
+
+func (lh *labeledValueHandler) matches(n *html.Node) bool {
+	if n.Type != html.ElementNode || n.FirstChild == nil || n.FirstChild.Type != html.TextNode {
+		return false
+	}
+	text := strings.Trim(n.FirstChild.Data, ": \t\r\n")
+	for _, label := range lh.labels {
+		if text == label {
+			return true
+		}
+	}
+	return false
+}
+
+func (lh *labeledValueHandler) process(n *html.Node) bool {
+	for sib := n.NextSibling; sib != nil; sib = sib.NextSibling {
+		var t string
+		if sib.Type == html.TextNode {
+			t = sib.Data
+		} else {
+			t = getText(sib)
+		}
+		t = strings.Trim(t, ": \t\r\n")
+		if t != "" {
+			lh.value = t
+			break
+		}
+	}
+	return false
+}
+
+// classTextHandler extracts the text of the first div with the given class, preserving paragraphs
+// if formatted is set
+type classTextHandler struct {
+	class     string
+	formatted bool
+	found     bool
+	text      string
+}
+
+func (cth *classTextHandler) matches(n *html.Node) bool {
+	return !cth.found && checkNodeTagNameAndClass(n, "div", cth.class)
+}
+
+func (cth *classTextHandler) process(n *html.Node) bool {
+	cth.found = true
+	if cth.formatted {
+		cth.text = getFormattedText(n)
+	} else {
+		cth.text = getText(n)
+	}
+	return false
+}
+
+// paginationHandler detects the links or buttons to the previous and next pages of a list
+type paginationHandler struct {
+	hasPrev bool
+	hasNext bool
+}
+
+func (*paginationHandler) matches(n *html.Node) bool {
+	return n.Type == html.ElementNode && (n.Data == "a" || n.Data == "button")
+}
+
+func (ph *paginationHandler) process(n *html.Node) bool {
+	if hasAttribute(n.Attr, "disabled") || NodeHasClass(n, "disabled") {
+		return false
+	}
+	text := strings.ToLower(getText(n))
+	// journals are listed newest first, so their pager has Older and Newer instead of Next and Prev
+	if strings.HasPrefix(text, "next") || strings.HasPrefix(text, "older") {
+		ph.hasNext = true
+	} else if strings.HasSuffix(text, "prev") || strings.HasSuffix(text, "previous") || strings.HasPrefix(text, "prev") ||
+		strings.HasPrefix(text, "newer") {
+		ph.hasPrev = true
+	}
+	return false
+}
+
+// pageCountHandler finds the highest page number linked to by a pager, from links or forms whose
+// target is prefix followed by the page number, whether one of them is a link to the last page, and
+// whether there is a next page
+type pageCountHandler struct {
+	prefix     string
+	last       uint
+	linksLast  bool
+	pagination paginationHandler
+}
+
+func (*pageCountHandler) matches(n *html.Node) bool {
+	return n.Type == html.ElementNode && (n.Data == "a" || n.Data == "form" || n.Data == "button")
+}
+
+func (ph *pageCountHandler) process(n *html.Node) bool {
+	if n.Data != "form" {
+		ph.pagination.process(n)
+	}
+	if n.Data == "button" {
+		return false
+	}
+	target := findAttribute(n.Attr, "href")
+	if n.Data == "form" {
+		target = findAttribute(n.Attr, "action")
+	}
+	u, err := url.Parse(target)
+	if err != nil || !strings.HasPrefix(strings.ToLower(u.Path), strings.ToLower(ph.prefix)) {
+		return true
+	}
+	page, err := strconv.ParseUint(strings.TrimSuffix(u.Path[len(ph.prefix):], "/"), 10, 32)
+	if err != nil {
+		return true
+	}
+	if uint(page) > ph.last {
+		ph.last = uint(page)
+	}
+	if strings.HasPrefix(strings.ToLower(getText(n)), "last") {
+		ph.linksLast = true
+	}
+	return true
+}
+
+// countPages determines the total number of pages from the pager on the first page. If the pager
+// does not link to the last page, it may only show a window of pages or just Previous and Next, so
+// pages are retrieved with fetch, starting from the highest linked page, until one has no next page.
+// fetch returns whether the page has any entries and whether it has a next page.
+func (ph *pageCountHandler) countPages(fetch func(page uint) (bool, bool, error)) (uint, error) {
+	if ph.linksLast {
+		return ph.last, nil
+	}
+	if !ph.pagination.hasNext && ph.last < 2 {
+		return 1, nil
+	}
+	page := ph.last
+	if page < 2 {
+		page = 2
+	}
+	for ; ; page++ {
+		found, hasNext, err := fetch(page)
+		if err != nil {
+			return 0, err
+		}
+		if !found {
+			return page - 1, nil
+		}
+		if !hasNext {
+			return page, nil
+		}
+	}
+}
+
+// normalizeURL resolves href, which may be absolute, protocol-relative, or a path, against base.
+func normalizeURL(base, href string) string {
+	if href == "" {
+		return ""
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		log.WithError(err).WithField("base", base).Error("Unable to parse base URL")
+		return href
+	}
+	h, err := url.Parse(href)
+	if err != nil {
+		log.WithError(err).WithField("href", href).Warn("Unable to parse URL")
+		return href
+	}
+	return b.ResolveReference(h).String()
+}
+
 func findAttribute(attrs []html.Attribute, name string) string {
 	for _, a := range attrs {
 		if a.Key == name {
@@ -71,10 +251,45 @@ func checkNodeTagNameAndID(n *html.Node, name, id string) bool {
 	return n.Type == html.ElementNode && n.Data == name && findAttribute(n.Attr, "id") == id
 }
 
+func checkNodeTagNameAndAttribute(n *html.Node, tagName, attrName, attrValue string) bool {
+	return n.Type == html.ElementNode && n.Data == tagName && findAttribute(n.Attr, attrName) == attrValue
+}
+
+// CheckNodeTagNameAndAttribute checks whether the node is an element with the given tag name and
+// attribute value.
+func CheckNodeTagNameAndAttribute(n *html.Node, tagName, attrName, attrValue string) bool {
+	return checkNodeTagNameAndAttribute(n, tagName, attrName, attrValue)
+}
+
+// checkNodeSectionID checks whether the node is a section with one of the given IDs.
+func checkNodeSectionID(n *html.Node, ids []string) bool {
+	if n.Type != html.ElementNode || n.Data != "section" {
+		return false
+	}
+	id := findAttribute(n.Attr, "id")
+	if id == "" {
+		return false
+	}
+	for _, sid := range ids {
+		if id == sid {
+			return true
+		}
+	}
+	return false
+}
+
 func checkNodeTagNameAndClass(n *html.Node, name, class string) bool {
-	c := findAttribute(n.Attr, "class")
-	hasClass := strings.Contains(c, class)
-	return n.Type == html.ElementNode && n.Data == name && hasClass
+	return n.Type == html.ElementNode && n.Data == name && NodeHasClass(n, class)
+}
+
+// NodeHasClass checks whether class is one of the classes in the node's class attribute.
+func NodeHasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(findAttribute(n.Attr, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
 }
 
 func findChild(n *html.Node, tag string, i int) *html.Node {
@@ -90,6 +305,52 @@ func findChild(n *html.Node, tag string, i int) *html.Node {
 	return nil
 }
 
+func findFirstChild(n *html.Node, tag string) *html.Node {
+	return findChild(n, tag, 0)
+}
+
+// FindFirstChild returns the first direct child of n with the given tag name, or nil if there is none.
+func FindFirstChild(n *html.Node, tag string) *html.Node {
+	return findFirstChild(n, tag)
+}
+
+// getFormattedText returns the text of the node, keeping line breaks at <br> tags and blank lines
+// between paragraphs and other block-level elements.
+func getFormattedText(n *html.Node) string {
+	var sb strings.Builder
+	writeFormattedText(&sb, n)
+	s := multipleNewlinesRegexp.ReplaceAllString(sb.String(), "\n\n")
+	lines := strings.Split(s, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func writeFormattedText(sb *strings.Builder, n *html.Node) {
+	for t := n.FirstChild; t != nil; t = t.NextSibling {
+		switch {
+		case t.Type == html.TextNode:
+			sb.WriteString(strings.Join(strings.Fields(t.Data), " "))
+			if len(t.Data) > 0 && strings.TrimSpace(t.Data[len(t.Data)-1:]) == "" {
+				sb.WriteString(" ")
+			}
+		case t.Type == html.ElementNode && t.Data == "br":
+			sb.WriteString("\n")
+		case t.Type == html.ElementNode && (t.Data == "li" || t.Data == "tr"):
+			sb.WriteString("\n")
+			writeFormattedText(sb, t)
+			sb.WriteString("\n")
+		case t.Type == html.ElementNode && blockElements[t.Data]:
+			sb.WriteString("\n\n")
+			writeFormattedText(sb, t)
+			sb.WriteString("\n\n")
+		default:
+			writeFormattedText(sb, t)
+		}
+	}
+}
+
 func getText(n *html.Node) string {
 	s := ""
 	for t := n.FirstChild; t != nil; t = t.NextSibling {
@@ -102,3 +363,68 @@ func getText(n *html.Node) string {
 	}
 	return strings.Trim(s, " \t \r\n")
 }
+
+var (
+	multipleNewlinesRegexp = regexp.MustCompile(`\n\s*\n(\s*\n)*`)
+
+	// voidElements never have an end tag.
+	voidElements = map[string]bool{
+		"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+		"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+	}
+	// blockElements are separated from surrounding text by a blank line when formatting text.
+	blockElements = map[string]bool{
+		"address": true, "article": true, "aside": true, "blockquote": true, "div": true, "dl": true,
+		"fieldset": true, "figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+		"h4": true, "h5": true, "h6": true, "header": true, "hr": true, "ol": true, "p": true, "pre": true,
+		"section": true, "table": true, "ul": true,
+	}
+	// optionalEndElements may be implicitly closed.
+	optionalEndElements = map[string]bool{
+		"body": true, "colgroup": true, "dd": true, "dt": true, "head": true, "html": true, "li": true,
+		"optgroup": true, "option": true, "p": true, "tbody": true, "td": true, "tfoot": true, "th": true,
+		"thead": true, "tr": true,
+	}
+)
+
+// htmlParseWarnings tokenizes an HTML document and describes any mismatched tags, which the parser
+// would otherwise silently correct.
+func htmlParseWarnings(bb []byte) []string {
+	var warnings []string
+	var open []string
+	z := html.NewTokenizer(bytes.NewReader(bb))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			for _, tag := range open {
+				if !optionalEndElements[tag] {
+					warnings = append(warnings, fmt.Sprintf("unclosed <%s> at end of document", tag))
+				}
+			}
+			return warnings
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			if tag := string(name); !voidElements[tag] {
+				open = append(open, tag)
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			i := len(open) - 1
+			for ; i >= 0 && open[i] != tag; i-- {
+			}
+			if i < 0 {
+				if !voidElements[tag] {
+					warnings = append(warnings, fmt.Sprintf("unexpected end tag </%s>", tag))
+				}
+				continue
+			}
+			for _, unclosed := range open[i+1:] {
+				if !optionalEndElements[unclosed] {
+					warnings = append(warnings, fmt.Sprintf("unclosed <%s> before </%s>", unclosed, tag))
+				}
+			}
+			open = open[:i]
+		}
+	}
+}
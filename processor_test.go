@@ -0,0 +1,256 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// loadFixture parses the named HTML file from testdata.
+func loadFixture(t *testing.T, name string) *html.Node {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	root, err := html.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// parseFragment parses an HTML snippet for tests which don't need a whole page.
+func parseFragment(t *testing.T, s string) *html.Node {
+	t.Helper()
+	root, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestSubtreeProcessorFirstMatchWins(t *testing.T) {
+	root := parseFragment(t, `<div><b>Species:</b> Red fox</div>`)
+	first := &labeledValueHandler{
+		labels: []string{"Species"},
+	}
+	second := &labeledValueHandler{
+		labels: []string{"Species"},
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			first,
+			second,
+		},
+	}
+	rp.processNode(root)
+
+	if first.value != "Red fox" {
+		t.Errorf("first handler value = %q, want %q", first.value, "Red fox")
+	}
+	if second.value != "" {
+		t.Error("second handler processed a node already handled by the first")
+	}
+}
+
+func TestPaginationHandler(t *testing.T) {
+	tests := []struct {
+		fixture string
+		hasPrev bool
+		hasNext bool
+	}{
+		{"gallery.html", false, true},
+		{"journals.html", false, true},
+		{"search.html", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			ph := &paginationHandler{}
+			rp := &subtreeProcessor{
+				tagHandlers: []tagHandler{
+					ph,
+				},
+			}
+			rp.processNode(loadFixture(t, tt.fixture))
+
+			if ph.hasPrev != tt.hasPrev {
+				t.Errorf("hasPrev = %v, want %v", ph.hasPrev, tt.hasPrev)
+			}
+			if ph.hasNext != tt.hasNext {
+				t.Errorf("hasNext = %v, want %v", ph.hasNext, tt.hasNext)
+			}
+		})
+	}
+}
+
+func TestPaginationHandlerPrevious(t *testing.T) {
+	root := parseFragment(t, `<a href="/gallery/artist/1/">Prev</a><a class="disabled" href="#">Next</a>`)
+	ph := &paginationHandler{}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			ph,
+		},
+	}
+	rp.processNode(root)
+
+	if !ph.hasPrev || ph.hasNext {
+		t.Errorf("hasPrev = %v, hasNext = %v, want true, false", ph.hasPrev, ph.hasNext)
+	}
+}
+
+func TestPageCountHandler(t *testing.T) {
+	tests := []struct {
+		fixture   string
+		prefix    string
+		last      uint
+		linksLast bool
+	}{
+		{"gallery.html", "/gallery/artist/", 3, false},
+		{"journals.html", "/journals/artist/", 4, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			ph := &pageCountHandler{
+				prefix: tt.prefix,
+			}
+			rp := &subtreeProcessor{
+				tagHandlers: []tagHandler{
+					ph,
+				},
+			}
+			rp.processNode(loadFixture(t, tt.fixture))
+
+			if ph.last != tt.last || ph.linksLast != tt.linksLast {
+				t.Errorf("last = %d, linksLast = %v, want %d, %v", ph.last, ph.linksLast, tt.last, tt.linksLast)
+			}
+			if !ph.pagination.hasNext {
+				t.Error("hasNext = false, want true")
+			}
+		})
+	}
+}
+
+func TestPageCountHandlerCountPages(t *testing.T) {
+	tests := []struct {
+		name    string
+		pager   string
+		total   uint
+		fetched []uint
+	}{
+		{"last link", `<a href="/journals/artist/2/">Older</a><a href="/journals/artist/9/">Last</a>`, 9, nil},
+		{"window", `<a href="/journals/artist/2/">2</a><a href="/journals/artist/3/">3</a><a href="/journals/artist/2/">Older</a>`,
+			5, []uint{3, 4, 5}},
+		{"next only", `<form action="/journals/artist/2/"><button>Next</button></form>`, 3, []uint{2, 3}},
+		{"single page", `<button class="disabled">Next</button>`, 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ph := &pageCountHandler{
+				prefix: "/journals/artist/",
+			}
+			rp := &subtreeProcessor{
+				tagHandlers: []tagHandler{
+					ph,
+				},
+			}
+			rp.processNode(parseFragment(t, tt.pager))
+
+			var fetched []uint
+			total, err := ph.countPages(func(page uint) (bool, bool, error) {
+				fetched = append(fetched, page)
+				// every page up to the expected total has a next page
+				return true, page < tt.total, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != tt.total {
+				t.Errorf("total = %d, want %d", total, tt.total)
+			}
+			if !reflect.DeepEqual(fetched, tt.fetched) {
+				t.Errorf("fetched = %v, want %v", fetched, tt.fetched)
+			}
+		})
+	}
+}
+
+func TestLabeledValueHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"text value", `<div><b>Species:</b> Red fox</div>`, "Red fox"},
+		{"element value", `<div><b>Uploaded by</b> <a href="/user/friend/">friend</a></div>`, "friend"},
+		{"other label", `<div><b>Gender:</b> Male</div>`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lh := &labeledValueHandler{
+				labels: []string{"Species", "Uploaded by"},
+			}
+			rp := &subtreeProcessor{
+				tagHandlers: []tagHandler{
+					lh,
+				},
+			}
+			rp.processNode(parseFragment(t, tt.html))
+
+			if lh.value != tt.want {
+				t.Errorf("value = %q, want %q", lh.value, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		href string
+		want string
+	}{
+		{"", ""},
+		{"/view/123/", "https://www.furaffinity.net/view/123/"},
+		{"//t.furaffinity.net/123@200-1577977440.jpg", "https://t.furaffinity.net/123@200-1577977440.jpg"},
+		{"https://d.furaffinity.net/art/a.png", "https://d.furaffinity.net/art/a.png"},
+	}
+	for _, tt := range tests {
+		if got := normalizeURL(baseURL, tt.href); got != tt.want {
+			t.Errorf("normalizeURL(%q) = %q, want %q", tt.href, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+)
+
+// UserProfile is the metadata shown on a user's profile page.
+type UserProfile struct {
+	DisplayName string
+	AvatarURL   string
+	Bio         string
+	// Species and Gender are as listed by the user on their profile, if shown.
+	Species string
+	Gender  string
+	// Title is the user's title, e.g. "Artist".
+	Title string
+	// RegisteredSince is when the user joined FA, or the zero value if it could not be parsed.
+	RegisteredSince time.Time
+	// JoinDate is the date as shown.
+	JoinDate string
+	// Views through Watching are the statistics shown on the profile.
+	Views       int64
+	Submissions int64
+	Favorites   int64
+	Watchers    int64
+	Watching    int64
+}
+
+// Trophy is an achievement shown on a user's profile.
+type Trophy struct {
+	Name        string
+	Description string
+	AwardedAt   time.Time
+}
+
+// UserLink is a link to one of the user's accounts elsewhere, as listed in their contact information.
+type UserLink struct {
+	Platform string
+	URL      string
+}
+
+const (
+	contactIconClassPrefix = "contact-service-icon-"
+)
+
+var (
+	userpageTitleRegexp = regexp.MustCompile(`^Userpage of (.+?) -- Fur Affinity`)
+)
+
+// GetProfile retrieves the metadata from the user's profile page. The result is cached.
+func (u *User) GetProfile() (*UserProfile, error) {
+	if u.profile != nil {
+		return u.profile, nil
+	}
+	log.WithField("user", u).Debug("Retrieving profile")
+
+	root, err := u.c.get("/user/" + u.name)
+	if err != nil {
+		return nil, err
+	}
+
+	title := &userpageTitleHandler{}
+	avatar := &avatarHandler{
+		name: u.name,
+	}
+	bio := &classTextHandler{
+		class:     "userpage-profile",
+		formatted: true,
+	}
+	species := &labeledValueHandler{
+		labels: []string{"Species"},
+	}
+	gender := &labeledValueHandler{
+		labels: []string{"Gender"},
+	}
+	table := &profileTableHandler{
+		handlers: []tagHandler{
+			species,
+			gender,
+		},
+	}
+	userTitle := &labeledValueHandler{
+		labels: []string{"User Title", "Title"},
+	}
+	since := &labeledValueHandler{
+		labels: []string{"Member Since", "Registered Since"},
+	}
+	profile := &UserProfile{}
+	stats := &statsTableHandler{
+		stats: map[string]*int64{
+			"Views":       &profile.Views,
+			"Pageviews":   &profile.Views,
+			"Submissions": &profile.Submissions,
+			"Favs":        &profile.Favorites,
+			"Favorites":   &profile.Favorites,
+		},
+	}
+	watchers := &watchCountHandler{
+		direction: "to",
+	}
+	watching := &watchCountHandler{
+		direction: "by",
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			title,
+			avatar,
+			bio,
+			userTitle,
+			since,
+			stats,
+			watchers,
+			watching,
+		},
+	}
+	rp.processNode(root)
+	// the table may be within the bio, which the bio handler does not recurse into
+	tp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			table,
+		},
+	}
+	tp.processNode(root)
+
+	profile.DisplayName = title.name
+	profile.AvatarURL = avatar.url
+	profile.Bio = bio.text
+	profile.Species = species.value
+	profile.Gender = gender.value
+	profile.Title = userTitle.value
+	profile.JoinDate = since.value
+	profile.Watchers = int64(watchers.count)
+	profile.Watching = int64(watching.count)
+	if since.value != "" {
+		t, err := ParseFADate(since.value)
+		if err != nil {
+			log.WithError(err).WithField("user", u).Warn("Unable to parse registration date")
+		}
+		profile.RegisteredSince = t
+	}
+	u.profile = profile
+	return profile, nil
+}
+
+// GetBio retrieves the biography from the user's profile.
+func (u *User) GetBio() (string, error) {
+	profile, err := u.GetProfile()
+	if err != nil {
+		return "", err
+	}
+	return profile.Bio, nil
+}
+
+// GetTrophies retrieves the trophies shown on the user's profile. Returns an empty slice without an
+// error if the profile does not show any.
+func (u *User) GetTrophies() ([]*Trophy, error) {
+	log.WithField("user", u).Debug("Retrieving trophies")
+
+	root, err := u.c.get("/user/" + u.name)
+	if err != nil {
+		return nil, err
+	}
+
+	th := &trophyHandler{
+		trophies: []*Trophy{},
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			th,
+		},
+	}
+	rp.processNode(root)
+
+	return th.trophies, nil
+}
+
+// GetUserLinks retrieves the links to the user's other accounts listed on their profile. Returns an
+// empty slice without an error if the profile does not list any.
+func (u *User) GetUserLinks() ([]*UserLink, error) {
+	log.WithField("user", u).Debug("Retrieving user links")
+
+	root, err := u.c.get("/user/" + u.name)
+	if err != nil {
+		return nil, err
+	}
+
+	ulh := &userLinksHandler{
+		links: []*UserLink{},
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			ulh,
+		},
+	}
+	rp.processNode(root)
+
+	return ulh.links, nil
+}
+
+// GetAvatar retrieves the user's avatar image. The result is cached.
+func (u *User) GetAvatar() ([]byte, error) {
+	if u.avatar != nil {
+		return *u.avatar, nil
+	}
+
+	profile, err := u.GetProfile()
+	if err != nil {
+		return nil, err
+	}
+	if profile.AvatarURL == "" {
+		return nil, fmt.Errorf("no avatar found for user %s", u.name)
+	}
+	// the avatar URL is usually protocol-relative
+	bb, err := u.c.getRaw(normalizeURL(baseURL, profile.AvatarURL))
+	if err != nil {
+		return nil, err
+	}
+	u.avatar = &bb
+	return bb, nil
+}
+
+// GetUserAvatar retrieves the avatar image of the named user.
+func (c *Client) GetUserAvatar(username string) ([]byte, error) {
+	return c.NewUser(username).GetAvatar()
+}
+
+// userpageTitleHandler extracts the user's display name from the page title
+type userpageTitleHandler struct {
+	name string
+}
+
+func (*userpageTitleHandler) matches(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "title" && n.FirstChild != nil
+}
+
+func (th *userpageTitleHandler) process(n *html.Node) bool {
+	if m := userpageTitleRegexp.FindStringSubmatch(strings.TrimSpace(n.FirstChild.Data)); m != nil {
+		th.name = m[1]
+	}
+	return false
+}
+
+// avatarHandler finds the avatar image of the named user
+type avatarHandler struct {
+	name string
+	url  string
+}
+
+func (ah *avatarHandler) matches(n *html.Node) bool {
+	return ah.url == "" && checkNodeTagNameAndClass(n, "img", "avatar") &&
+		strings.EqualFold(findAttribute(n.Attr, "alt"), ah.name)
+}
+
+func (ah *avatarHandler) process(n *html.Node) bool {
+	ah.url = normalizeURL(baseURL, findAttribute(n.Attr, "src"))
+	return false
+}
+
+// profileTableHandler runs handlers within the table of profile information, so that they do not
+// match anything in the rest of the page such as the bio
+type profileTableHandler struct {
+	handlers []tagHandler
+}
+
+func (*profileTableHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "div", "userpage-profile-table")
+}
+
+func (pth *profileTableHandler) process(n *html.Node) bool {
+	p := subtreeProcessor{
+		tagHandlers: pth.handlers,
+	}
+	p.processNode(n)
+	return false
+}
+
+// statsTableHandler reads the count following each of the labels in stats, e.g.
+// <span>Views:</span> 1,234, into the corresponding destination
+type statsTableHandler struct {
+	stats map[string]*int64
+}
+
+func (sth *statsTableHandler) matches(n *html.Node) bool {
+	if n.Type != html.ElementNode || n.FirstChild == nil || n.FirstChild.Type != html.TextNode {
+		return false
+	}
+	_, ok := sth.stats[strings.Trim(n.FirstChild.Data, ": \t\r\n")]
+	return ok
+}
+
+func (sth *statsTableHandler) process(n *html.Node) bool {
+	lh := &labeledValueHandler{}
+	lh.process(n)
+	if m := countRegexp.FindString(lh.value); m != "" {
+		*sth.stats[strings.Trim(n.FirstChild.Data, ": \t\r\n")] = int64(parseCount(m))
+	}
+	return false
+}
+
+// trophyHandler extracts each trophy on the profile
+type trophyHandler struct {
+	trophies []*Trophy
+}
+
+func (*trophyHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "div", "trophy")
+}
+
+func (th *trophyHandler) process(n *html.Node) bool {
+	dh := &popupDateHandler{}
+	p := subtreeProcessor{
+		tagHandlers: []tagHandler{
+			dh,
+		},
+	}
+	p.processNode(n)
+
+	t := &Trophy{
+		Description: findAttribute(n.Attr, "title"),
+		AwardedAt:   dh.date,
+	}
+	if img := findFirstChild(n, "img"); img != nil {
+		t.Name = findAttribute(img.Attr, "alt")
+	}
+	if t.Name == "" {
+		t.Name = getText(n)
+	}
+	th.trophies = append(th.trophies, t)
+	return false
+}
+
+// userLinksHandler extracts each linked entry in the user's contact information
+type userLinksHandler struct {
+	links []*UserLink
+}
+
+func (*userLinksHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "div", "user-contact-item")
+}
+
+func (ulh *userLinksHandler) process(n *html.Node) bool {
+	lh := &contactLinkHandler{}
+	p := subtreeProcessor{
+		tagHandlers: []tagHandler{
+			lh,
+		},
+	}
+	p.processNode(n)
+
+	if lh.url == "" {
+		// not all contact information is a link
+		return false
+	}
+	platform := lh.platform
+	if platform == "" {
+		platform = lh.icon
+	}
+	if platform == "" {
+		if u, err := url.Parse(lh.url); err == nil {
+			platform = strings.TrimPrefix(u.Hostname(), "www.")
+		}
+	}
+	ulh.links = append(ulh.links, &UserLink{
+		Platform: platform,
+		URL:      lh.url,
+	})
+	return false
+}
+
+// contactLinkHandler extracts the label, icon, and link from a contact information entry
+type contactLinkHandler struct {
+	platform string
+	icon     string
+	url      string
+}
+
+func (*contactLinkHandler) matches(n *html.Node) bool {
+	return n.Type == html.ElementNode && (n.Data == "a" || n.Data == "strong" || n.Data == "div")
+}
+
+func (clh *contactLinkHandler) process(n *html.Node) bool {
+	switch n.Data {
+	case "a":
+		if clh.url == "" {
+			clh.url = normalizeURL(baseURL, findAttribute(n.Attr, "href"))
+		}
+	case "strong":
+		if clh.platform == "" {
+			clh.platform = getText(n)
+		}
+	case "div":
+		for _, class := range strings.Fields(findAttribute(n.Attr, "class")) {
+			if strings.HasPrefix(class, contactIconClassPrefix) {
+				clh.icon = strings.TrimPrefix(class, contactIconClassPrefix)
+			}
+		}
+		return true
+	}
+	return false
+}
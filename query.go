@@ -0,0 +1,107 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"strings"
+)
+
+// SearchField is a field that an extended search query can be restricted to.
+type SearchField string
+
+// SearchField values
+const (
+	SearchFieldTitle       SearchField = "title"
+	SearchFieldKeywords    SearchField = "keywords"
+	SearchFieldDescription SearchField = "message"
+	SearchFieldArtist      SearchField = "lower"
+)
+
+// SearchBuilder constructs an extended search query out of field-restricted terms.
+type SearchBuilder struct {
+	clauses   []string
+	operators []string
+}
+
+// BuildSearchQuery formats terms as an extended search query restricted to field, e.g.
+// `@keywords dragon "red eyes"`. Terms containing whitespace are quoted as phrases.
+func BuildSearchQuery(field SearchField, terms ...string) string {
+	parts := make([]string, 0, len(terms)+1)
+	parts = append(parts, "@"+string(field))
+	for _, t := range terms {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if strings.ContainsAny(t, " \t\r\n") {
+			t = `"` + strings.ReplaceAll(t, `"`, `\"`) + `"`
+		}
+		parts = append(parts, t)
+	}
+	return strings.Join(parts, " ")
+}
+
+// NewSearchBuilder creates an empty SearchBuilder.
+func NewSearchBuilder() *SearchBuilder {
+	return &SearchBuilder{}
+}
+
+// WithFieldTerm adds a clause matching terms in field, which must match along with the preceding
+// clauses (AND).
+func (sb *SearchBuilder) WithFieldTerm(field SearchField, terms ...string) *SearchBuilder {
+	return sb.add("&", BuildSearchQuery(field, terms...))
+}
+
+// OrFieldTerm adds a clause matching terms in field, which may match instead of the preceding
+// clauses (OR).
+func (sb *SearchBuilder) OrFieldTerm(field SearchField, terms ...string) *SearchBuilder {
+	return sb.add("|", BuildSearchQuery(field, terms...))
+}
+
+func (sb *SearchBuilder) add(operator, clause string) *SearchBuilder {
+	sb.clauses = append(sb.clauses, clause)
+	sb.operators = append(sb.operators, operator)
+	return sb
+}
+
+// String returns the query, suitable for passing to Client.NewSearch.
+func (sb *SearchBuilder) String() string {
+	if len(sb.clauses) == 1 {
+		return sb.clauses[0]
+	}
+
+	var q strings.Builder
+	for i, clause := range sb.clauses {
+		if i > 0 {
+			q.WriteString(" " + sb.operators[i] + " ")
+		}
+		q.WriteString("(" + clause + ")")
+	}
+	return q.String()
+}
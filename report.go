@@ -0,0 +1,101 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// InvalidReportReasonError is returned when a report reason is not one FA accepts.
+type InvalidReportReasonError struct {
+	Reason string
+	// Valid are the reasons FA does accept.
+	Valid []string
+}
+
+func (e *InvalidReportReasonError) Error() string {
+	return fmt.Sprintf("report reason %q not accepted; must be one of %s", e.Reason, strings.Join(e.Valid, ", "))
+}
+
+// ReportSubmission reports a submission to the FA staff for the given reason, which must be one of
+// the values offered by the report form. Returns an *InvalidReportReasonError if it is not.
+func (c *Client) ReportSubmission(id int64, reason string) error {
+	if reason == "" {
+		return errors.New("report reason must not be empty")
+	}
+	log.WithField("id", id).WithField("reason", reason).Debug("Reporting submission")
+
+	uri := fmt.Sprintf("/controls/troubleticket/?submission=%d", id)
+	root, err := c.get(uri)
+	if err != nil {
+		return err
+	}
+
+	fh := &formHandler{
+		id: "report-form",
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			fh,
+		},
+	}
+	rp.processNode(root)
+
+	if !fh.found {
+		return ErrNotLoggedIn
+	}
+
+	valid := fh.options["reason"]
+	accepted := false
+	for _, v := range valid {
+		if v == reason {
+			accepted = true
+			break
+		}
+	}
+	if !accepted {
+		return &InvalidReportReasonError{
+			Reason: reason,
+			Valid:  valid,
+		}
+	}
+
+	fh.values.Set("reason", reason)
+	action := fh.action
+	if action == "" {
+		// forms without an action submit to the page they're on
+		action = uri
+	}
+	_, err = c.post(action, fh.values)
+	return err
+}
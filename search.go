@@ -29,7 +29,9 @@
 package faapi
 
 import (
+	"fmt"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -40,66 +42,260 @@ import (
 type Search struct {
 	c     *Client
 	query string
+	opts  SearchOptions
 }
 
+// SearchOptions configures a search. The zero value searches with FA's defaults.
+type SearchOptions struct {
+	// IncludeGeneral, IncludeMature, and IncludeAdult select which ratings are included in the
+	// results. If none are set, all are included.
+	IncludeGeneral bool
+	IncludeMature  bool
+	IncludeAdult   bool
+	// OrderBy and OrderDirection control how the results are sorted. They default to
+	// OrderByDate and OrderDirectionDesc.
+	OrderBy        SearchOrderBy
+	OrderDirection SearchOrderDirection
+	// TypeArt through TypePoetry select which types of submissions are included in the results. If
+	// none are set, all are included.
+	TypeArt    bool
+	TypeFlash  bool
+	TypePhoto  bool
+	TypeMusic  bool
+	TypeStory  bool
+	TypePoetry bool
+	// Mode is how the query's keywords are matched. It defaults to SearchModeExtended.
+	Mode SearchMode
+	// Range limits the results to those submitted recently. It defaults to RangeAll.
+	Range SearchRange
+	// PerPage is how many results are on each page: 24, 48, or 72. It defaults to 72.
+	PerPage int
+}
+
+// SearchMode is how a search query's keywords are matched.
+type SearchMode string
+
+// SearchMode values
+const (
+	SearchModeExtended SearchMode = "extended"
+	SearchModeFast     SearchMode = "fast"
+	SearchModeAny      SearchMode = "any"
+	SearchModeAll      SearchMode = "all"
+	SearchModeExact    SearchMode = "exact"
+)
+
+// SearchRange is how recently the results of a search were submitted.
+type SearchRange string
+
+// SearchRange values
+const (
+	RangeAll       SearchRange = "all"
+	RangeDay       SearchRange = "1day"
+	RangeThreeDays SearchRange = "3days"
+	RangeWeek      SearchRange = "1week"
+	RangeMonth     SearchRange = "1month"
+	RangeYear      SearchRange = "1year"
+)
+
+// AllTypes returns a copy of the options with every type of submission included.
+func (o SearchOptions) AllTypes() SearchOptions {
+	o.TypeArt = true
+	o.TypeFlash = true
+	o.TypePhoto = true
+	o.TypeMusic = true
+	o.TypeStory = true
+	o.TypePoetry = true
+	return o
+}
+
+// SearchOrderBy is what search results are sorted by.
+type SearchOrderBy string
+
+// SearchOrderBy values
+const (
+	OrderByDate       SearchOrderBy = "date"
+	OrderByRelevance  SearchOrderBy = "relevancy"
+	OrderByPopularity SearchOrderBy = "popularity"
+)
+
+// SearchOrderDirection is the direction search results are sorted in.
+type SearchOrderDirection string
+
+// SearchOrderDirection values
+const (
+	OrderDirectionAsc  SearchOrderDirection = "asc"
+	OrderDirectionDesc SearchOrderDirection = "desc"
+)
+
+// SearchPage is a page of search results.
+type SearchPage struct {
+	Results []*Submission
+	// FirstResult and LastResult are the 1-based indexes of the results on this page, out of
+	// TotalResults.
+	FirstResult  int
+	LastResult   int
+	TotalResults int
+	HasNextPage  bool
+	TotalPages   int
+}
+
+const (
+	// defaultSearchPerPage is how many results are requested per page if not specified.
+	defaultSearchPerPage = 72
+)
+
+var (
+	searchResultCountRegexp = regexp.MustCompile(`(\d[\d,]*)\s*[-–]\s*(\d[\d,]*)\s+of\s+(\d[\d,]*)`)
+)
+
 // NewSearch creates a new search for the given query.
 func (c *Client) NewSearch(query string) *Search {
+	return c.NewSearchWithOptions(query, SearchOptions{})
+}
+
+// NewSearchWithOptions creates a new search for the given query with the given options.
+func (c *Client) NewSearchWithOptions(query string, opts SearchOptions) *Search {
 	return &Search{
 		c:     c,
 		query: query,
+		opts:  opts,
 	}
 }
 
 // GetPage returns the search results on the given page. The page numbering starts at 1.
 func (s *Search) GetPage(page int) ([]*Submission, error) {
-	var subs []*Submission
+	sp, err := s.GetPageInfo(page)
+	if err != nil {
+		return nil, err
+	}
+	return sp.Results, nil
+}
+
+// GetPageInfo returns the search results on the given page, along with the result counts. The page
+// numbering starts at 1.
+func (s *Search) GetPageInfo(page int) (*SearchPage, error) {
 	log.WithFields(log.Fields{
 		"query": s.query,
 		"page":  page,
 	}).Debug("Performing search")
 
+	perPage, err := s.opts.perPage()
+	if err != nil {
+		return nil, err
+	}
+
 	params := url.Values{}
 	params.Set("q", s.query)
 	params.Set("page", strconv.Itoa(page))
-	params.Set("perpage", "72")
-	params.Set("order-by", "date")
-	params.Set("order-direction", "desc")
+	params.Set("perpage", strconv.Itoa(perPage))
 	params.Set("do_search", "Search")
-	params.Set("range", "all")
-	params.Set("rating-general", "on")
-	params.Set("rating-mature", "on")
-	params.Set("rating-adult", "on")
-	params.Set("type-art", "on")
-	params.Set("type-flash", "on")
-	params.Set("type-photo", "on")
-	params.Set("type-music", "on")
-	params.Set("type-story", "on")
-	params.Set("type-poetry", "on")
-	params.Set("mode", "extended")
+	s.opts.apply(params)
 
 	root, err := s.c.post("/search/", params)
 	if err != nil {
-		return subs, err
+		return nil, err
 	}
 
 	srh := &searchResultsHandler{}
+	srhh := &searchResultsHeaderHandler{}
+	pagination := &paginationHandler{}
 	p := subtreeProcessor{
 		tagHandlers: []tagHandler{
 			srh,
+			srhh,
+			pagination,
 		},
 	}
 	p.processNode(root)
+	if srh.err != nil {
+		return nil, srh.err
+	}
 
-	subs = srh.results
+	subs := srh.results
 	for i := range subs {
 		subs[i].c = s.c
 	}
 
-	return subs, nil
+	return &SearchPage{
+		Results:      subs,
+		FirstResult:  srhh.first,
+		LastResult:   srhh.last,
+		TotalResults: srhh.total,
+		HasNextPage:  pagination.hasNext || srhh.last < srhh.total,
+		TotalPages:   (srhh.total + perPage - 1) / perPage,
+	}, nil
+}
+
+// perPage returns the effective number of results per page, or an error if PerPage is not one of the
+// sizes FA supports.
+func (o *SearchOptions) perPage() (int, error) {
+	switch o.PerPage {
+	case 0:
+		return defaultSearchPerPage, nil
+	case 24, 48, 72:
+		return o.PerPage, nil
+	default:
+		return 0, fmt.Errorf("invalid results per page %d: must be 24, 48, or 72", o.PerPage)
+	}
+}
+
+// apply sets the search parameters for the options.
+func (o *SearchOptions) apply(params url.Values) {
+	all := !o.IncludeGeneral && !o.IncludeMature && !o.IncludeAdult
+	if all || o.IncludeGeneral {
+		params.Set("rating-general", "on")
+	}
+	if all || o.IncludeMature {
+		params.Set("rating-mature", "on")
+	}
+	if all || o.IncludeAdult {
+		params.Set("rating-adult", "on")
+	}
+
+	orderBy := o.OrderBy
+	if orderBy == "" {
+		orderBy = OrderByDate
+	}
+	params.Set("order-by", string(orderBy))
+	orderDirection := o.OrderDirection
+	if orderDirection == "" {
+		orderDirection = OrderDirectionDesc
+	}
+	params.Set("order-direction", string(orderDirection))
+
+	types := *o
+	if !o.TypeArt && !o.TypeFlash && !o.TypePhoto && !o.TypeMusic && !o.TypeStory && !o.TypePoetry {
+		types = o.AllTypes()
+	}
+	for param, on := range map[string]bool{
+		"type-art":    types.TypeArt,
+		"type-flash":  types.TypeFlash,
+		"type-photo":  types.TypePhoto,
+		"type-music":  types.TypeMusic,
+		"type-story":  types.TypeStory,
+		"type-poetry": types.TypePoetry,
+	} {
+		if on {
+			params.Set(param, "on")
+		}
+	}
+
+	mode := o.Mode
+	if mode == "" {
+		mode = SearchModeExtended
+	}
+	params.Set("mode", string(mode))
+
+	searchRange := o.Range
+	if searchRange == "" {
+		searchRange = RangeAll
+	}
+	params.Set("range", string(searchRange))
 }
 
 type searchResultsHandler struct {
 	results []*Submission
+	err     error
 }
 
 func (*searchResultsHandler) matches(n *html.Node) bool {
@@ -115,11 +311,45 @@ func (srh *searchResultsHandler) process(n *html.Node) bool {
 	}
 	p.processNode(n)
 	srh.results = srsh.results
+	srh.err = srsh.err
+	return false
+}
+
+// searchResultsHeaderHandler extracts the result counts from the "results 1 - 72 of 4,523" header
+type searchResultsHeaderHandler struct {
+	first int
+	last  int
+	total int
+}
+
+func (*searchResultsHeaderHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndID(n, "div", "query-stats")
+}
+
+func (srhh *searchResultsHeaderHandler) process(n *html.Node) bool {
+	m := searchResultCountRegexp.FindStringSubmatch(getText(n))
+	if m == nil {
+		return false
+	}
+	srhh.first = parseCount(m[1])
+	srhh.last = parseCount(m[2])
+	srhh.total = parseCount(m[3])
 	return false
 }
 
+// parseCount parses a number that may contain thousands separators.
+func parseCount(str string) int {
+	i, err := strconv.Atoi(strings.ReplaceAll(strings.TrimSpace(str), ",", ""))
+	if err != nil {
+		log.WithError(err).WithField("count", str).Warn("Unable to parse count")
+	}
+	return i
+}
+
 type searchResultsSectionHandler struct {
 	results []*Submission
+	// err is the first error encountered parsing a result
+	err error
 }
 
 func (*searchResultsSectionHandler) matches(n *html.Node) bool {
@@ -145,8 +375,15 @@ func (srsh *searchResultsSectionHandler) process(n *html.Node) bool {
 	}
 	p.processNode(n)
 
+	id, err := parseSubmissionID(findAttribute(n.Attr, "id"))
+	if err != nil {
+		if srsh.err == nil {
+			srsh.err = fmt.Errorf("unparseable search result: %w", err)
+		}
+		return false
+	}
 	srsh.results = append(srsh.results, &Submission{
-		ID:         parseSubmissionID(findAttribute(n.Attr, "id")),
+		ID:         id,
 		Rating:     Rating(strings.Replace(rating, "r-", "", 1)),
 		PreviewURL: ssph.url,
 		Title:      ssh.title,
@@ -186,7 +423,7 @@ func (*searchSubmissionPreviewHandler) matches(n *html.Node) bool {
 }
 
 func (ssph *searchSubmissionPreviewHandler) process(n *html.Node) bool {
-	ssph.url = "https:" + findAttribute(n.Attr, "src")
+	ssph.url = normalizeURL(baseURL, findAttribute(n.Attr, "src"))
 	return false
 }
 
@@ -0,0 +1,170 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripperFunc lets a function stand in for the transport, so requests never leave the test
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newTestClient creates a Client which sends its requests to rt.
+func newTestClient(t *testing.T, rt http.RoundTripper) *Client {
+	t.Helper()
+	c, err := New(Config{
+		RateLimit: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.http.Transport = rt
+	return c
+}
+
+// fixtureResponse responds to req with the named HTML file from testdata.
+func fixtureResponse(t *testing.T, req *http.Request, name string) *http.Response {
+	t.Helper()
+	body, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/html; charset=UTF-8"}},
+		Body:       ioutil.NopCloser(strings.NewReader(string(body))),
+		Request:    req,
+	}
+}
+
+func TestSearchResultsHandlers(t *testing.T) {
+	srh := &searchResultsHandler{}
+	srhh := &searchResultsHeaderHandler{}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			srh,
+			srhh,
+		},
+	}
+	rp.processNode(loadFixture(t, "search.html"))
+	if srh.err != nil {
+		t.Fatal(srh.err)
+	}
+
+	if srhh.first != 1 || srhh.last != 2 || srhh.total != 1234 {
+		t.Errorf("counts = %d-%d of %d, want 1-2 of 1234", srhh.first, srhh.last, srhh.total)
+	}
+
+	want := []Submission{
+		{ID: 301, Rating: RatingGeneral, Title: "A dragon", User: "artist", PreviewURL: "https://t.furaffinity.net/301@200-1577977440.jpg"},
+		// the rating class is not always first
+		{ID: 302, Rating: RatingAdult, Title: "A story", User: "writer", PreviewURL: "https://t.furaffinity.net/302@200-1577977441.jpg"},
+	}
+	if len(srh.results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(srh.results), len(want))
+	}
+	for i, s := range srh.results {
+		w := want[i]
+		if s.ID != w.ID || s.Rating != w.Rating || s.Title != w.Title || s.User != w.User || s.PreviewURL != w.PreviewURL {
+			t.Errorf("result %d = %+v, want %+v", i, *s, w)
+		}
+	}
+}
+
+func TestSearchGetPageRatings(t *testing.T) {
+	ratings := []string{"rating-general", "rating-mature", "rating-adult"}
+	tests := []struct {
+		name string
+		opts SearchOptions
+		want []string
+	}{
+		{"default", SearchOptions{}, ratings},
+		{"general only", SearchOptions{IncludeGeneral: true}, []string{"rating-general"}},
+		{"mature and adult", SearchOptions{IncludeMature: true, IncludeAdult: true}, []string{"rating-mature", "rating-adult"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var form url.Values
+			c := newTestClient(t, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				body, err := ioutil.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				if form, err = url.ParseQuery(string(body)); err != nil {
+					return nil, err
+				}
+				return fixtureResponse(t, req, "search.html"), nil
+			}))
+
+			if _, err := c.NewSearchWithOptions("dragon", tt.opts).GetPage(1); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, param := range ratings {
+				if form.Get(param) != "" {
+					got = append(got, param)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("POST body has ratings %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchOptionsPerPage(t *testing.T) {
+	tests := []struct {
+		perPage int
+		want    int
+		wantErr bool
+	}{
+		{0, defaultSearchPerPage, false},
+		{24, 24, false},
+		{72, 72, false},
+		{50, 0, true},
+	}
+	for _, tt := range tests {
+		o := &SearchOptions{PerPage: tt.perPage}
+		got, err := o.perPage()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("perPage() with PerPage %d = %d, %v, want %d, error %v", tt.perPage, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
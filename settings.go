@@ -0,0 +1,91 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"net/url"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	settingsURI = "/controls/settings/"
+)
+
+// GetMatureContentSetting returns whether the account is configured to show mature content.
+func (c *Client) GetMatureContentSetting() (bool, error) {
+	values, err := c.getSettingsForm()
+	if err != nil {
+		return false, err
+	}
+
+	v := values.Get("viewmature")
+	return v != "" && v != "0", nil
+}
+
+// SetMatureContentSetting changes whether the account is configured to show mature content. All
+// other account settings are submitted unchanged.
+func (c *Client) SetMatureContentSetting(enabled bool) error {
+	log.WithField("enabled", enabled).Debug("Changing mature content setting")
+	values, err := c.getSettingsForm()
+	if err != nil {
+		return err
+	}
+
+	if enabled {
+		values.Set("viewmature", "1")
+	} else {
+		values.Set("viewmature", "0")
+	}
+	_, err = c.post(settingsURI, values)
+	return err
+}
+
+// getSettingsForm retrieves the current values of the account settings form.
+func (c *Client) getSettingsForm() (url.Values, error) {
+	root, err := c.get(settingsURI)
+	if err != nil {
+		return nil, err
+	}
+
+	fh := &formHandler{
+		action: settingsURI,
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			fh,
+		},
+	}
+	rp.processNode(root)
+
+	if !fh.found {
+		return nil, ErrNotLoggedIn
+	}
+	return fh.values, nil
+}
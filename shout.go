@@ -0,0 +1,165 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+)
+
+// Shout is a message left on a user's profile.
+type Shout struct {
+	ID      int64
+	User    string
+	Content string
+	Date    time.Time
+}
+
+func (s *Shout) String() string {
+	return fmt.Sprintf("%s: %s (%d)", s.User, s.Content, s.ID)
+}
+
+// GetShouts retrieves the shouts visible on the user's profile page.
+func (u *User) GetShouts() ([]*Shout, error) {
+	log.WithField("user", u).Debug("Retrieving shouts")
+	return u.getShouts(context.Background(), "/user/"+u.name)
+}
+
+// GetShoutsPage retrieves the specified page of the user's shout history. Page numbering starts at 1.
+func (u *User) GetShoutsPage(page uint) ([]*Shout, error) {
+	return u.getShoutsPage(context.Background(), page)
+}
+
+// AllShouts retrieves every page of the user's shouts, sending each shout to the returned channel as
+// it is retrieved. The error channel receives at most one error, and both channels are closed when
+// iteration stops. Cancelling ctx stops iteration.
+func (u *User) AllShouts(ctx context.Context) (<-chan *Shout, <-chan error) {
+	shoutCh := make(chan *Shout)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(errCh)
+		defer close(shoutCh)
+
+		for page := uint(1); ; page++ {
+			shouts, err := u.getShoutsPage(ctx, page)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			if len(shouts) == 0 {
+				return
+			}
+			for _, shout := range shouts {
+				select {
+				case shoutCh <- shout:
+				case <-ctx.Done():
+					errCh <- ctx.Err()
+					return
+				}
+			}
+		}
+	}()
+
+	return shoutCh, errCh
+}
+
+func (u *User) getShoutsPage(ctx context.Context, page uint) ([]*Shout, error) {
+	if page == 0 {
+		page = 1
+	}
+	log.WithField("user", u).WithField("page", page).Debug("Retrieving shouts")
+	return u.getShouts(ctx, fmt.Sprintf("/shouts/%s/%d/", u.name, page))
+}
+
+func (u *User) getShouts(ctx context.Context, uri string) ([]*Shout, error) {
+	root, err := u.c.getWithContext(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	sh := &shoutHandler{
+		shouts: []*Shout{},
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			sh,
+		},
+	}
+	rp.processNode(root)
+
+	return sh.shouts, nil
+}
+
+// shoutHandler extracts each shout, which are in divs with IDs like "shout-12345"
+type shoutHandler struct {
+	shouts []*Shout
+}
+
+func (*shoutHandler) matches(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "div" && strings.HasPrefix(findAttribute(n.Attr, "id"), "shout-")
+}
+
+func (sh *shoutHandler) process(n *html.Node) bool {
+	id, err := parseSubmissionID(strings.TrimPrefix(findAttribute(n.Attr, "id"), "shout-"))
+	if err != nil {
+		log.WithError(err).Warn("Skipping unparseable shout")
+		return false
+	}
+
+	ul := &userLinkHandler{}
+	text := &classTextHandler{
+		class: "comment_text",
+	}
+	dh := &popupDateHandler{}
+	p := subtreeProcessor{
+		tagHandlers: []tagHandler{
+			ul,
+			text,
+			dh,
+		},
+	}
+	p.processNode(n)
+
+	s := &Shout{
+		ID:      id,
+		Content: text.text,
+		Date:    dh.date,
+	}
+	if len(ul.names) > 0 {
+		s.User = ul.names[0]
+	}
+	sh.shouts = append(sh.shouts, s)
+	return false
+}
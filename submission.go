@@ -29,10 +29,18 @@
 package faapi
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/html"
@@ -40,13 +48,20 @@ import (
 
 // Submission is an artwork submission.
 type Submission struct {
-	c            *Client
-	ID           int64
-	PreviewURL   string
-	Rating       Rating
-	Title        string
-	User         string
-	previewImage *[]byte
+	c          *Client
+	ID         int64
+	PreviewURL string
+	Rating     Rating
+	Title      string
+	User       string
+	// PreviewURLActual is the URL the preview image was actually retrieved from by PreviewImage, which
+	// may be larger than PreviewURL.
+	PreviewURLActual string
+	previewImage     *[]byte
+	// largestPreviewURL is the largest preview URL known to be available
+	largestPreviewURL *string
+	// details are the submission's details, if they were parsed along with it
+	details *SubmissionDetails
 }
 
 // SubmissionDetails are the details of a specific submission.
@@ -54,10 +69,66 @@ type Submission struct {
 type SubmissionDetails struct {
 	c *Client
 	// The blob linked to by DownloadURL. NOT the full size image on the page (text/music submissions)
-	download    *[]byte
+	download *[]byte
+	// authorProfile is the cached profile of User
+	authorProfile *UserProfile
+	// User is the name of the user who posted the submission.
+	User        string
 	DownloadURL string
 	Description string
 	Stats       string
+	// DownloadCount is the number of times the submission file has been downloaded, as distinct from
+	// page views. It is 0 if FA does not track downloads for the submission.
+	DownloadCount int64
+	// Folders are the gallery folders the submission is in.
+	Folders []FolderRef
+	// IsSubmittedByFriend is whether the submission was uploaded by someone on behalf of the artist.
+	// When it is, UploadedBy is the username of the account which uploaded it, and OriginalArtist is
+	// the credited artist whose gallery it appears in.
+	IsSubmittedByFriend bool
+	UploadedBy          string
+	OriginalArtist      string
+	// ContestEntry is whether the submission is entered in a contest.
+	ContestEntry bool
+	// LastEditedAt is when the submission was last edited, or the zero value if FA does not show it.
+	LastEditedAt time.Time
+}
+
+// FolderRef identifies a gallery folder.
+type FolderRef struct {
+	ID   int64
+	Name string
+}
+
+// submissionJSON is the serialized form of a Submission. It excludes the client and any cached data.
+type submissionJSON struct {
+	ID         int64  `json:"id"`
+	PreviewURL string `json:"preview_url,omitempty"`
+	Rating     Rating `json:"rating,omitempty"`
+	Title      string `json:"title,omitempty"`
+	User       string `json:"user,omitempty"`
+
+	PreviewURLActual string `json:"preview_url_actual,omitempty"`
+}
+
+// submissionDetailsJSON is the serialized form of SubmissionDetails. It excludes the client and any
+// cached data.
+type submissionDetailsJSON struct {
+	User        string      `json:"user,omitempty"`
+	DownloadURL string      `json:"download_url,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Stats       string      `json:"stats,omitempty"`
+	Folders     []FolderRef `json:"folders,omitempty"`
+
+	DownloadCount int64 `json:"download_count,omitempty"`
+
+	IsSubmittedByFriend bool   `json:"is_submitted_by_friend,omitempty"`
+	UploadedBy          string `json:"uploaded_by,omitempty"`
+	OriginalArtist      string `json:"original_artist,omitempty"`
+	ContestEntry        bool   `json:"contest_entry,omitempty"`
+
+	// LastEditedAt is a pointer so that omitempty leaves out the zero time
+	LastEditedAt *time.Time `json:"last_edited_at,omitempty"`
 }
 
 // Rating is the decency rating of a submission.
@@ -71,10 +142,19 @@ const (
 )
 
 const (
-	previewURLFormat = "https://t.furaffinity.net/%s@800-%s.%s"
+	previewURLFormat = "https://t.furaffinity.net/%s@%s-%s.%s"
+	// downloadAttempts is how many times Download tries to retrieve the file.
+	downloadAttempts = 3
 )
 
 var (
+	// previewSizes are the larger preview sizes available on the CDN, largest first.
+	previewSizes = []string{"1600", "800"}
+
+	folderRegexp      = regexp.MustCompile(`^/(?:gallery|scraps)/[^/]+/folder/(\d+)/(?:[^/]+/)?`)
+	favoritesRegexp   = regexp.MustCompile(`Favorites:\s*([\d,]+)`)
+	downloadsRegexp   = regexp.MustCompile(`Downloads:\s*([\d,]+)`)
+	viewRegexp        = regexp.MustCompile(`^/view/(\d+)/?$`)
 	previewSizeRegexp = regexp.MustCompile(`^https://t.furaffinity.net/(\d+)@(\d+)-(\d+)\.([a-zA-Z]+)$`)
 )
 
@@ -82,45 +162,241 @@ func (s *Submission) String() string {
 	return fmt.Sprintf("%s %s by %s (%s, %d)", s.PreviewURL, s.Title, s.User, s.Rating, s.ID)
 }
 
+// MarshalJSON implements json.Marshaler.
+func (s *Submission) MarshalJSON() ([]byte, error) {
+	return json.Marshal(submissionJSON{
+		ID:         s.ID,
+		PreviewURL: s.PreviewURL,
+		Rating:     s.Rating,
+		Title:      s.Title,
+		User:       s.User,
+
+		PreviewURLActual: s.PreviewURLActual,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The resulting Submission is not associated with a
+// Client; use Client.AttachSubmission before calling any methods which make requests.
+func (s *Submission) UnmarshalJSON(data []byte) error {
+	var sj submissionJSON
+	if err := json.Unmarshal(data, &sj); err != nil {
+		return err
+	}
+	s.ID = sj.ID
+	s.PreviewURL = sj.PreviewURL
+	s.Rating = sj.Rating
+	s.Title = sj.Title
+	s.User = sj.User
+	s.PreviewURLActual = sj.PreviewURLActual
+	s.previewImage = nil
+	s.largestPreviewURL = nil
+	return nil
+}
+
+// AttachSubmission associates a Submission restored from JSON with this client.
+func (c *Client) AttachSubmission(s *Submission) *Submission {
+	s.c = c
+	return s
+}
+
 func (s *Submission) PreviewImage() ([]byte, error) {
+	return s.fetchPreviewImage(context.Background(), false)
+}
+
+// fetchPreviewImage retrieves the preview image, waiting for the rate limit before each attempt if
+// rateLimit is set.
+func (s *Submission) fetchPreviewImage(ctx context.Context, rateLimit bool) ([]byte, error) {
 	if s.previewImage != nil {
 		return *s.previewImage, nil
 	}
 	logger := log.WithField("submission", s)
 
 	// try to get the largest preview available
+	candidates := s.previewURLCandidates()
+	var err error
+	for i, url := range candidates {
+		if rateLimit {
+			if err := s.c.waitRateLimit(ctx); err != nil {
+				return nil, err
+			}
+		}
+		logger.WithField("url", url).Debug("Trying preview URL")
+		var bb []byte
+		bb, err = s.c.getRawWithContext(ctx, url)
+		if err != nil {
+			if i < len(candidates)-1 {
+				logger.WithError(err).WithField("url", url).WithField("next", candidates[i+1]).
+					Warn("Unable to retrieve preview; falling back to smaller size")
+			}
+			continue
+		}
+		logger.WithField("url", url).Debug("Retrieved preview")
+		s.previewImage = &bb
+		s.largestPreviewURL = &url
+		s.PreviewURLActual = url
+		return bb, nil
+	}
+	return nil, err
+}
+
+// LargestPreviewURL determines the URL of the largest preview image available for the submission,
+// without downloading it.
+func (s *Submission) LargestPreviewURL() (string, error) {
+	if s.largestPreviewURL != nil {
+		return *s.largestPreviewURL, nil
+	}
+	logger := log.WithField("submission", s)
+
+	candidates := s.previewURLCandidates()
+	var err error
+	for _, url := range candidates {
+		err = s.c.head(url)
+		if err != nil {
+			logger.WithError(err).WithField("url", url).Debug("Preview size not available")
+			continue
+		}
+		s.largestPreviewURL = &url
+		return url, nil
+	}
+	return "", err
+}
+
+// previewURLCandidates returns the preview URLs to try, largest first, ending with PreviewURL.
+func (s *Submission) previewURLCandidates() []string {
+	var urls []string
 	parts := previewSizeRegexp.FindStringSubmatch(s.PreviewURL)
 	if len(parts) == 5 {
-		// don't bother for preview URLs already at the large size
-		if parts[2] != "800" {
-			url := fmt.Sprintf(previewURLFormat, parts[1], parts[3], parts[4])
-			bb, err := s.c.getRaw(url)
-			if err != nil {
-				logger.WithError(err).Warn("Unable to retrieve large-size preview; falling back to provided size")
-			} else {
-				s.previewImage = &bb
-				return bb, nil
+		for _, size := range previewSizes {
+			// sizes are tried in descending order, so stop once we reach the provided size
+			if parts[2] == size {
+				break
 			}
+			urls = append(urls, fmt.Sprintf(previewURLFormat, parts[1], size, parts[3], parts[4]))
 		}
 	} else {
-		logger.Warn("Regexp failed to parse preview URL")
+		log.WithField("submission", s).Warn("Regexp failed to parse preview URL")
 	}
+	return append(urls, s.PreviewURL)
+}
 
-	bb, err := s.c.getRaw(s.PreviewURL)
+// Refresh re-fetches the submission's page and updates the Title, Rating, User, and PreviewURL in-place.
+// Any cached preview image is discarded.
+func (s *Submission) Refresh(ctx context.Context) error {
+	log.WithField("submission", s).Debug("Refreshing submission")
+	root, err := s.c.getWithContext(ctx, fmt.Sprintf("/view/%d/", s.ID))
+	if err != nil {
+		return err
+	}
+
+	s.updateFromPage(root)
+	return nil
+}
+
+// GetSubmissionsBetween retrieves each submission with an ID from endID down to startID, inclusive.
+// Submissions which have been removed are skipped. If ctx is cancelled, the submissions retrieved so
+// far are returned along with the context's error.
+func (c *Client) GetSubmissionsBetween(startID, endID int64, ctx context.Context) ([]*Submission, error) {
+	var subs []*Submission
+	for id := endID; id >= startID; id-- {
+		if err := ctx.Err(); err != nil {
+			return subs, err
+		}
+
+		root, err := c.getWithContext(ctx, fmt.Sprintf("/view/%d/", id))
+		if err != nil {
+			return subs, err
+		}
+		if _, err = c.ParseSubmissionDetails(root); err != nil {
+			if errors.Is(err, ErrContentRemoved) {
+				log.WithField("id", id).Debug("Skipping removed submission")
+				continue
+			}
+			return subs, err
+		}
+
+		s := &Submission{
+			c:  c,
+			ID: id,
+		}
+		s.updateFromPage(root)
+		subs = append(subs, s)
+	}
+	return subs, nil
+}
+
+// GetRandomSubmission retrieves a random submission. Its details are parsed from the same page, so
+// calling Details on the result does not make another request.
+func (c *Client) GetRandomSubmission() (*Submission, error) {
+	req, err := c.newRequest(http.MethodGet, "/random/", nil)
 	if err != nil {
 		return nil, err
 	}
-	s.previewImage = &bb
-	return bb, nil
+
+	root, finalURL, err := c.doFinalURL(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// FA redirects to the view page of the chosen submission
+	m := viewRegexp.FindStringSubmatch(finalURL.Path)
+	if m == nil {
+		return nil, fmt.Errorf("random submission resolved to unexpected URL %s", finalURL)
+	}
+	id, err := parseSubmissionID(m[1])
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Submission{
+		c:  c,
+		ID: id,
+	}
+	s.updateFromPage(root)
+	s.details, err = c.ParseSubmissionDetails(root)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// updateFromPage updates the listing data from the submission's view page.
+func (s *Submission) updateFromPage(root *html.Node) {
+	title := &submissionTitleHandler{}
+	rating := &ratingHandler{}
+	preview := &submissionPreviewHandler{}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			title,
+			rating,
+			preview,
+		},
+	}
+	rp.processNode(root)
+
+	if title.title != "" {
+		s.Title = title.title
+	}
+	if title.user != "" {
+		s.User = title.user
+	}
+	if rating.rating != "" {
+		s.Rating = rating.rating
+	}
+	if preview.url != "" {
+		s.PreviewURL = preview.url
+	}
+	s.PreviewURLActual = ""
+	s.previewImage = nil
+	s.largestPreviewURL = nil
 }
 
-func parseSubmissionID(str string) int64 {
+// parseSubmissionID parses a submission (or other) ID, which may have an "sid-" prefix.
+func parseSubmissionID(str string) (int64, error) {
 	id, err := strconv.ParseInt(strings.Replace(str, "sid-", "", 1), 10, 64)
-	// if this ever happens, everything will be completely broken, so returning 0 is... fine?
 	if err != nil {
-		log.WithError(err).Error("Unable to parse submission ID")
+		return 0, fmt.Errorf("unable to parse ID %q: %w", str, err)
 	}
-	return id
+	return id, nil
 }
 
 func (c *Client) GetSubmissionDetails(id int64) (*SubmissionDetails, error) {
@@ -128,37 +404,200 @@ func (c *Client) GetSubmissionDetails(id int64) (*SubmissionDetails, error) {
 	if err != nil {
 		return nil, err
 	}
+	return c.ParseSubmissionDetails(root)
+}
 
+// ParseSubmissionDetails extracts the details of a submission from its already-retrieved page.
+func (c *Client) ParseSubmissionDetails(root *html.Node) (*SubmissionDetails, error) {
+	title := &submissionTitleHandler{}
 	down := &downloadHandler{}
 	desc := &descriptionHandler{}
 	stats := &statsHandler{}
+	folders := &submissionFolderListHandler{
+		folders: []FolderRef{},
+	}
+	uploadedBy := &labeledValueHandler{
+		labels: []string{"Uploaded by"},
+	}
+	contest := &contestEntryHandler{}
+	removed := &contentRemovedHandler{}
 	rp := &subtreeProcessor{
 		tagHandlers: []tagHandler{
+			title,
 			down,
 			desc,
 			stats,
+			folders,
+			uploadedBy,
+			contest,
+			removed,
 		},
 	}
 	rp.processNode(root)
 
-	return &SubmissionDetails{
+	if removed.found && title.title == "" {
+		return nil, ErrContentRemoved
+	}
+
+	sd := &SubmissionDetails{
 		c:           c,
-		DownloadURL: "https:" + down.url,
+		User:        title.user,
+		DownloadURL: normalizeURL(baseURL, down.url),
 		Description: desc.text,
 		Stats:       stats.stats,
-	}, nil
+		Folders:     folders.folders,
+
+		ContestEntry: contest.found,
+		LastEditedAt: stats.edited.date,
+	}
+	if m := downloadsRegexp.FindStringSubmatch(stats.stats); m != nil {
+		sd.DownloadCount = int64(parseCount(m[1]))
+	}
+	if uploadedBy.value != "" {
+		sd.IsSubmittedByFriend = true
+		sd.UploadedBy = uploadedBy.value
+		sd.OriginalArtist = title.user
+	}
+	return sd, nil
+}
+
+// GetFavoriteCount retrieves only the number of favorites on a submission.
+func (c *Client) GetFavoriteCount(submissionID int64) (int, error) {
+	root, err := c.get(fmt.Sprintf("/view/%d/", submissionID))
+	if err != nil {
+		return 0, err
+	}
+
+	stats := &statsHandler{}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			stats,
+		},
+	}
+	rp.processNode(root)
+
+	m := favoritesRegexp.FindStringSubmatch(stats.stats)
+	if m == nil {
+		return 0, fmt.Errorf("favorite count not found for submission %d", submissionID)
+	}
+	return parseCount(m[1]), nil
 }
 
+// GetSubmissionRating retrieves only the rating of a submission.
+func (c *Client) GetSubmissionRating(id int64) (Rating, error) {
+	root, err := c.get(fmt.Sprintf("/view/%d/", id))
+	if err != nil {
+		return "", err
+	}
+
+	rating := &submissionRatingHandler{}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			rating,
+		},
+	}
+	rp.processNode(root)
+
+	if rating.rating == "" {
+		return "", fmt.Errorf("rating not found for submission %d", id)
+	}
+	return rating.rating, nil
+}
+
+// Details retrieves the details of the submission, unless they were already parsed along with it.
 func (s *Submission) Details() (*SubmissionDetails, error) {
+	if s.details != nil {
+		return s.details, nil
+	}
 	return s.c.GetSubmissionDetails(s.ID)
 }
 
+// GetAuthorProfile retrieves the profile of the user who posted the submission. The result is cached.
+func (sd *SubmissionDetails) GetAuthorProfile() (*UserProfile, error) {
+	if sd.authorProfile != nil {
+		return sd.authorProfile, nil
+	}
+
+	profile, err := sd.c.NewUser(sd.User).GetProfile()
+	if err != nil {
+		return nil, err
+	}
+	sd.authorProfile = profile
+	return profile, nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (sd *SubmissionDetails) MarshalJSON() ([]byte, error) {
+	sdj := submissionDetailsJSON{
+		User:        sd.User,
+		DownloadURL: sd.DownloadURL,
+		Description: sd.Description,
+		Stats:       sd.Stats,
+		Folders:     sd.Folders,
+
+		DownloadCount: sd.DownloadCount,
+
+		IsSubmittedByFriend: sd.IsSubmittedByFriend,
+		UploadedBy:          sd.UploadedBy,
+		OriginalArtist:      sd.OriginalArtist,
+		ContestEntry:        sd.ContestEntry,
+	}
+	if !sd.LastEditedAt.IsZero() {
+		edited := sd.LastEditedAt
+		sdj.LastEditedAt = &edited
+	}
+	return json.Marshal(sdj)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The resulting SubmissionDetails is not associated with
+// a Client; use Client.AttachSubmissionDetails before calling any methods which make requests.
+func (sd *SubmissionDetails) UnmarshalJSON(data []byte) error {
+	var sdj submissionDetailsJSON
+	if err := json.Unmarshal(data, &sdj); err != nil {
+		return err
+	}
+	sd.User = sdj.User
+	sd.DownloadURL = sdj.DownloadURL
+	sd.Description = sdj.Description
+	sd.Stats = sdj.Stats
+	sd.Folders = sdj.Folders
+	sd.DownloadCount = sdj.DownloadCount
+	sd.IsSubmittedByFriend = sdj.IsSubmittedByFriend
+	sd.UploadedBy = sdj.UploadedBy
+	sd.OriginalArtist = sdj.OriginalArtist
+	sd.ContestEntry = sdj.ContestEntry
+	sd.LastEditedAt = time.Time{}
+	if sdj.LastEditedAt != nil {
+		sd.LastEditedAt = *sdj.LastEditedAt
+	}
+	sd.download = nil
+	sd.authorProfile = nil
+	return nil
+}
+
+// AttachSubmissionDetails associates SubmissionDetails restored from JSON with this client.
+func (c *Client) AttachSubmissionDetails(sd *SubmissionDetails) *SubmissionDetails {
+	sd.c = c
+	return sd
+}
+
+// Download retrieves the file linked to by DownloadURL. If the connection fails part way through, the
+// download is resumed up to downloadAttempts times. The result is cached.
 func (sd *SubmissionDetails) Download() ([]byte, error) {
 	if sd.download != nil {
 		return *sd.download, nil
 	}
 
-	bb, err := sd.c.getRaw(sd.DownloadURL)
+	raw, encoding, err := sd.downloadRaw()
+	if err != nil {
+		return nil, err
+	}
+	body, err := sd.c.decodeContent(encoding, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	bb, err := ioutil.ReadAll(body)
 	if err != nil {
 		return nil, err
 	}
@@ -166,6 +605,85 @@ func (sd *SubmissionDetails) Download() ([]byte, error) {
 	return bb, nil
 }
 
+// downloadRaw retrieves the file, resuming it if needed, and returns its Content-Encoding. Only
+// transport and read errors are retried; an unexpected HTTP response, including a body which is too
+// large, will not be any different the next time.
+func (sd *SubmissionDetails) downloadRaw() ([]byte, string, error) {
+	var bb []byte
+	var encoding string
+	var err error
+	for i := 0; i < downloadAttempts; i++ {
+		bb, encoding, err = sd.resumeDownload(context.Background(), bb, encoding)
+		if err == nil {
+			return bb, encoding, nil
+		}
+		if errors.Is(err, ErrHTTPError) {
+			break
+		}
+		log.WithError(err).WithFields(log.Fields{
+			"url":      sd.DownloadURL,
+			"received": len(bb),
+		}).Warn("Download failed")
+	}
+	return nil, "", err
+}
+
+// ResumeDownload retrieves the rest of the file linked to by DownloadURL, after the existingBytes
+// which have already been retrieved, and returns the entire file. If the server does not support
+// resuming, the entire file is retrieved again. On error, the bytes retrieved so far are returned, so
+// that they can be passed to ResumeDownload again.
+func (sd *SubmissionDetails) ResumeDownload(ctx context.Context, existingBytes []byte) ([]byte, error) {
+	bb, _, err := sd.resumeDownload(ctx, existingBytes, "")
+	return bb, err
+}
+
+// resumeDownload retrieves the rest of the file after existingBytes, which were retrieved with the
+// Content-Encoding encoding, and returns the entire file and its Content-Encoding.
+func (sd *SubmissionDetails) resumeDownload(ctx context.Context, existingBytes []byte, encoding string) ([]byte, string, error) {
+	req, err := sd.c.newRequestWithContext(ctx, http.MethodGet, sd.DownloadURL, nil)
+	if err != nil {
+		return existingBytes, encoding, err
+	}
+	if len(existingBytes) > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", len(existingBytes)))
+	}
+
+	res, err := sd.c.doRaw(req)
+	if err != nil {
+		return existingBytes, encoding, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the range starts at the end of the file, so existingBytes is all of it
+		return existingBytes, encoding, nil
+	case http.StatusPartialContent:
+	default:
+		existingBytes = nil
+	}
+	bb, err := ioutil.ReadAll(res.Body)
+	return append(existingBytes[:len(existingBytes):len(existingBytes)], bb...), res.Header.Get("Content-Encoding"), err
+}
+
+// DownloadTo streams the blob linked to by DownloadURL to w, without buffering it in memory.
+// The result is not cached.
+func (sd *SubmissionDetails) DownloadTo(ctx context.Context, w io.Writer) error {
+	req, err := sd.c.newRequestWithContext(ctx, http.MethodGet, sd.DownloadURL, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := sd.c.doRaw(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	_, err = io.Copy(w, res.Body)
+	return err
+}
+
 type downloadHandler struct {
 	url string
 }
@@ -207,32 +725,32 @@ func (dh *descriptionHandler) process(n *html.Node) bool {
 	//            tr #2
 	//             td
 
-	n = findChild(n, "table", 0)
+	n = findFirstChild(n, "table")
 	if n == nil {
 		return false
 	}
 
-	n = findChild(n, "tbody", 0)
+	n = findFirstChild(n, "tbody")
 	if n == nil {
 		return false
 	}
 
-	n = findChild(n, "tr", 0)
+	n = findFirstChild(n, "tr")
 	if n == nil {
 		return false
 	}
 
-	n = findChild(n, "td", 0)
+	n = findFirstChild(n, "td")
 	if n == nil {
 		return false
 	}
 
-	n = findChild(n, "table", 0)
+	n = findFirstChild(n, "table")
 	if n == nil {
 		return false
 	}
 
-	n = findChild(n, "tbody", 0)
+	n = findFirstChild(n, "tbody")
 	if n == nil {
 		return false
 	}
@@ -242,17 +760,17 @@ func (dh *descriptionHandler) process(n *html.Node) bool {
 		return false
 	}
 
-	n = findChild(n, "td", 0)
+	n = findFirstChild(n, "td")
 	if n == nil {
 		return false
 	}
 
-	n = findChild(n, "table", 0)
+	n = findFirstChild(n, "table")
 	if n == nil {
 		return false
 	}
 
-	n = findChild(n, "tbody", 0)
+	n = findFirstChild(n, "tbody")
 	if n == nil {
 		return false
 	}
@@ -262,7 +780,7 @@ func (dh *descriptionHandler) process(n *html.Node) bool {
 		return false
 	}
 
-	n = findChild(n, "td", 0)
+	n = findFirstChild(n, "td")
 	if n == nil {
 		return false
 	}
@@ -273,7 +791,8 @@ func (dh *descriptionHandler) process(n *html.Node) bool {
 }
 
 type statsHandler struct {
-	stats string
+	stats  string
+	edited editDateHandler
 }
 
 func (*statsHandler) matches(n *html.Node) bool {
@@ -286,5 +805,191 @@ func (sh *statsHandler) process(n *html.Node) bool {
 	s = strings.ReplaceAll(s, "\t", " ")
 	s = strings.Trim(s, " \t \r\n")
 	sh.stats = s
+
+	p := subtreeProcessor{
+		tagHandlers: []tagHandler{
+			&sh.edited,
+		},
+	}
+	p.processNode(n)
 	return true
 }
+
+type submissionTitleHandler struct {
+	title string
+	user  string
+}
+
+func (*submissionTitleHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "div", "classic-submission-title")
+}
+
+func (sth *submissionTitleHandler) process(n *html.Node) bool {
+	if h := findFirstChild(n, "h2"); h != nil {
+		sth.title = getText(h)
+	}
+	if a := findFirstChild(n, "a"); a != nil {
+		sth.user = getText(a)
+	}
+	return false
+}
+
+// ratingHandler extracts the rating from the classes on the rating box.
+type ratingHandler struct {
+	rating Rating
+}
+
+func (rh *ratingHandler) matches(n *html.Node) bool {
+	// only the first rating box on the page is for the submission
+	return rh.rating == "" && checkNodeTagNameAndClass(n, "span", "rating-box")
+}
+
+func (rh *ratingHandler) process(n *html.Node) bool {
+	for _, class := range strings.Fields(findAttribute(n.Attr, "class")) {
+		switch r := Rating(class); r {
+		case RatingGeneral, RatingMature, RatingAdult:
+			rh.rating = r
+		}
+	}
+	return false
+}
+
+// submissionRatingHandler finds the rating of the submission, and once it is found, matches every
+// remaining node without recursing into it so the rest of the page is skipped
+type submissionRatingHandler struct {
+	ratingHandler
+}
+
+func (rh *submissionRatingHandler) matches(n *html.Node) bool {
+	return rh.rating != "" || rh.ratingHandler.matches(n)
+}
+
+func (rh *submissionRatingHandler) process(n *html.Node) bool {
+	if rh.rating != "" {
+		return false
+	}
+	return rh.ratingHandler.process(n)
+}
+
+type submissionPreviewHandler struct {
+	url string
+}
+
+func (*submissionPreviewHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndID(n, "img", "submissionImg")
+}
+
+func (sph *submissionPreviewHandler) process(n *html.Node) bool {
+	if src := findAttribute(n.Attr, "data-preview-src"); src != "" {
+		sph.url = normalizeURL(baseURL, src)
+	}
+	return false
+}
+
+// submissionFolderListHandler finds the list of folders the submission is in
+type submissionFolderListHandler struct {
+	folders []FolderRef
+}
+
+func (*submissionFolderListHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "div", "folder-list-container")
+}
+
+func (fh *submissionFolderListHandler) process(n *html.Node) bool {
+	fl := &folderLinkHandler{}
+	p := subtreeProcessor{
+		tagHandlers: []tagHandler{
+			fl,
+		},
+	}
+	p.processNode(n)
+	for _, f := range fl.folders {
+		fh.folders = append(fh.folders, f.FolderRef)
+	}
+	return false
+}
+
+// folderLinkHandler extracts each distinct folder linked to
+type folderLinkHandler struct {
+	folders []*Folder
+}
+
+func (*folderLinkHandler) matches(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "a" && folderRegexp.MatchString(findAttribute(n.Attr, "href"))
+}
+
+func (fl *folderLinkHandler) process(n *html.Node) bool {
+	href := findAttribute(n.Attr, "href")
+	id, err := parseSubmissionID(folderRegexp.FindStringSubmatch(href)[1])
+	if err != nil {
+		log.WithError(err).Warn("Skipping unparseable folder link")
+		return false
+	}
+	for _, f := range fl.folders {
+		if f.ID == id {
+			return false
+		}
+	}
+	fl.folders = append(fl.folders, &Folder{
+		FolderRef: FolderRef{
+			ID:   id,
+			Name: getText(n),
+		},
+		path: folderRegexp.FindString(href),
+	})
+	return false
+}
+
+// editDateHandler finds the first "Last edited" label in the stats and parses the date that follows it
+type editDateHandler struct {
+	found bool
+	date  time.Time
+}
+
+func (eh *editDateHandler) matches(n *html.Node) bool {
+	return !eh.found && n.Type == html.ElementNode && n.FirstChild != nil && n.FirstChild.Type == html.TextNode &&
+		strings.Trim(n.FirstChild.Data, ": \t\r\n") == "Last edited"
+}
+
+func (eh *editDateHandler) process(n *html.Node) bool {
+	for sib := n.NextSibling; sib != nil; sib = sib.NextSibling {
+		if checkNodeTagNameAndClass(sib, "span", "popup_date") {
+			dh := &popupDateHandler{}
+			dh.process(sib)
+			eh.found = true
+			eh.date = dh.date
+			break
+		}
+	}
+	return false
+}
+
+// contentRemovedHandler detects the system message shown in place of a submission which does not
+// exist or has been removed
+type contentRemovedHandler struct {
+	found bool
+}
+
+func (*contentRemovedHandler) matches(n *html.Node) bool {
+	return n.Type == html.TextNode && strings.Contains(n.Data, "not in our database")
+}
+
+func (ch *contentRemovedHandler) process(*html.Node) bool {
+	ch.found = true
+	return false
+}
+
+// contestEntryHandler detects the notice, in div.contest-notice, that the submission is part of a
+// contest
+type contestEntryHandler struct {
+	found bool
+}
+
+func (*contestEntryHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "div", "contest-notice")
+}
+
+func (ch *contestEntryHandler) process(*html.Node) bool {
+	ch.found = true
+	return false
+}
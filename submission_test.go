@@ -0,0 +1,330 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubmissionDetailsHandlers(t *testing.T) {
+	title := &submissionTitleHandler{}
+	down := &downloadHandler{}
+	desc := &descriptionHandler{}
+	stats := &statsHandler{}
+	folders := &submissionFolderListHandler{}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			title,
+			down,
+			desc,
+			stats,
+			folders,
+		},
+	}
+	rp.processNode(loadFixture(t, "view.html"))
+
+	if title.title != "Test Submission" || title.user != "artist" {
+		t.Errorf("title, user = %q, %q, want %q, %q", title.title, title.user, "Test Submission", "artist")
+	}
+	wantDownload := "//d.furaffinity.net/art/artist/1577977440/1577977440.artist_test.png"
+	if down.url != wantDownload {
+		t.Errorf("download URL = %q, want %q", down.url, wantDownload)
+	}
+	if desc.text != "A description of the submission." {
+		t.Errorf("description = %q", desc.text)
+	}
+	for _, s := range []string{"Category:", "Artwork (Digital)", "Favorites:", "12"} {
+		if !strings.Contains(stats.stats, s) {
+			t.Errorf("stats %q do not contain %q", stats.stats, s)
+		}
+	}
+	wantFolders := []FolderRef{{ID: 123, Name: "Sketches"}, {ID: 456, Name: "Commissions"}}
+	if !reflect.DeepEqual(folders.folders, wantFolders) {
+		t.Errorf("folders = %+v, want %+v", folders.folders, wantFolders)
+	}
+}
+
+func TestParseSubmissionDetails(t *testing.T) {
+	c := &Client{}
+	sd, err := c.ParseSubmissionDetails(loadFixture(t, "view.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sd.User != "artist" {
+		t.Errorf("User = %q, want %q", sd.User, "artist")
+	}
+	wantDownload := "https://d.furaffinity.net/art/artist/1577977440/1577977440.artist_test.png"
+	if sd.DownloadURL != wantDownload {
+		t.Errorf("DownloadURL = %q, want %q", sd.DownloadURL, wantDownload)
+	}
+	if sd.Description != "A description of the submission." {
+		t.Errorf("Description = %q", sd.Description)
+	}
+	if sd.DownloadCount != 1234 {
+		t.Errorf("DownloadCount = %d, want 1234", sd.DownloadCount)
+	}
+	if len(sd.Folders) != 2 {
+		t.Errorf("Folders = %+v, want 2 folders", sd.Folders)
+	}
+	if !sd.IsSubmittedByFriend || sd.OriginalArtist != "artist" {
+		t.Errorf("IsSubmittedByFriend = %v, OriginalArtist = %q, want true, artist", sd.IsSubmittedByFriend, sd.OriginalArtist)
+	}
+	if sd.UploadedBy != "friend" {
+		t.Errorf("UploadedBy = %q, want %q", sd.UploadedBy, "friend")
+	}
+	if !sd.ContestEntry {
+		t.Error("ContestEntry = false, want true")
+	}
+	// only the date in the stats counts, not the one in the comments
+	wantEdited := time.Date(2020, time.January, 2, 15, 4, 0, 0, time.UTC)
+	if !sd.LastEditedAt.Equal(wantEdited) {
+		t.Errorf("LastEditedAt = %v, want %v", sd.LastEditedAt, wantEdited)
+	}
+}
+
+func TestSubmissionDetailsJSONLastEdited(t *testing.T) {
+	b, err := json.Marshal(&SubmissionDetails{User: "artist"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "last_edited_at") {
+		t.Errorf("zero LastEditedAt was serialized: %s", b)
+	}
+
+	edited := time.Date(2020, time.January, 2, 15, 4, 0, 0, time.UTC)
+	b, err = json.Marshal(&SubmissionDetails{User: "artist", LastEditedAt: edited})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sd SubmissionDetails
+	if err := json.Unmarshal(b, &sd); err != nil {
+		t.Fatal(err)
+	}
+	if !sd.LastEditedAt.Equal(edited) {
+		t.Errorf("LastEditedAt = %v, want %v", sd.LastEditedAt, edited)
+	}
+}
+
+func TestParseSubmissionDetailsRemoved(t *testing.T) {
+	root := parseFragment(t, `<section class="notice-message"><div class="section-body">
+		<p>The submission you are trying to find is not in our database.</p></div></section>`)
+	c := &Client{}
+	if _, err := c.ParseSubmissionDetails(root); !errors.Is(err, ErrContentRemoved) {
+		t.Errorf("err = %v, want ErrContentRemoved", err)
+	}
+}
+
+func TestSubmissionUpdateFromPage(t *testing.T) {
+	s := &Submission{
+		ID:     12345,
+		Rating: RatingGeneral,
+		Title:  "Old title",
+	}
+	s.updateFromPage(loadFixture(t, "view.html"))
+
+	if s.Title != "Test Submission" {
+		t.Errorf("Title = %q, want %q", s.Title, "Test Submission")
+	}
+	if s.User != "artist" {
+		t.Errorf("User = %q, want %q", s.User, "artist")
+	}
+	// only the first rating box on the page is for the submission
+	if s.Rating != RatingMature {
+		t.Errorf("Rating = %q, want %q", s.Rating, RatingMature)
+	}
+	if s.PreviewURL != "https://t.furaffinity.net/12345@400-1577977440.jpg" {
+		t.Errorf("PreviewURL = %q", s.PreviewURL)
+	}
+}
+
+func TestSubmissionRatingHandler(t *testing.T) {
+	rating := &submissionRatingHandler{}
+	p := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			rating,
+		},
+	}
+	p.processNode(loadFixture(t, "view.html"))
+
+	// the adult rating box in the comments must not replace the submission's rating
+	if rating.rating != RatingMature {
+		t.Errorf("rating = %q, want %q", rating.rating, RatingMature)
+	}
+}
+
+func TestGetRandomSubmission(t *testing.T) {
+	requests := 0
+	c := newTestClient(t, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		// the transport sees the request after the redirect has been followed
+		final := req.Clone(req.Context())
+		final.URL.Path = "/view/12345/"
+		return fixtureResponse(t, final, "view.html"), nil
+	}))
+
+	s, err := c.GetRandomSubmission()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ID != 12345 || s.Title != "Test Submission" {
+		t.Errorf("ID = %d, Title = %q, want 12345, %q", s.ID, s.Title, "Test Submission")
+	}
+	sd, err := s.Details()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sd.User != "artist" {
+		t.Errorf("User = %q, want %q", sd.User, "artist")
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+func TestParseSubmissionID(t *testing.T) {
+	tests := []struct {
+		str     string
+		want    int64
+		wantErr bool
+	}{
+		{"12345", 12345, false},
+		{"sid-12345", 12345, false},
+		{"sid-", 0, true},
+		{"abc", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSubmissionID(tt.str)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseSubmissionID(%q) = %d, %v, want %d, error %v", tt.str, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// failingReader returns data and then fails, like a connection which drops part way through a body
+type failingReader struct {
+	data string
+}
+
+func (fr *failingReader) Read(p []byte) (int, error) {
+	if fr.data == "" {
+		return 0, errors.New("connection reset")
+	}
+	n := copy(p, fr.data)
+	fr.data = fr.data[n:]
+	return n, nil
+}
+
+func TestSubmissionDetailsDownload(t *testing.T) {
+	const file = "0123456789"
+	tests := []struct {
+		name string
+		// respond answers each request, given its Range header
+		respond  func(rng string) (int, io.Reader)
+		want     string
+		wantErr  error
+		attempts int
+	}{
+		{"resumed after a read error", func(rng string) (int, io.Reader) {
+			if rng == "" {
+				return http.StatusOK, &failingReader{data: file[:4]}
+			}
+			return http.StatusPartialContent, strings.NewReader(file[4:])
+		}, file, nil, 2},
+		{"nothing left to resume", func(rng string) (int, io.Reader) {
+			if rng == "" {
+				return http.StatusOK, &failingReader{data: file}
+			}
+			return http.StatusRequestedRangeNotSatisfiable, strings.NewReader("")
+		}, file, nil, 2},
+		{"not found", func(string) (int, io.Reader) {
+			return http.StatusNotFound, strings.NewReader("")
+		}, "", ErrHTTPError, 1},
+		{"server error", func(string) (int, io.Reader) {
+			return http.StatusInternalServerError, strings.NewReader("")
+		}, "", ErrHTTPError, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			c := newTestClient(t, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				attempts++
+				code, body := tt.respond(req.Header.Get("Range"))
+				return &http.Response{
+					StatusCode: code,
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(body),
+					Request:    req,
+				}, nil
+			}))
+			sd := c.AttachSubmissionDetails(&SubmissionDetails{DownloadURL: "https://d.furaffinity.net/art/artist/1/1.test.png"})
+
+			bb, err := sd.Download()
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if string(bb) != tt.want {
+				t.Errorf("Download() = %q, want %q", bb, tt.want)
+			}
+			if attempts != tt.attempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestSubmissionDetailsDownloadTooLarge(t *testing.T) {
+	attempts := 0
+	c := newTestClient(t, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader("0123456789")),
+			Request:    req,
+		}, nil
+	}))
+	c.config.MaxResponseBodySize = 4
+	sd := c.AttachSubmissionDetails(&SubmissionDetails{DownloadURL: "https://d.furaffinity.net/art/artist/1/1.test.png"})
+
+	if _, err := sd.Download(); !errors.Is(err, ErrHTTPError) {
+		t.Errorf("err = %v, want ErrHTTPError", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
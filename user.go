@@ -29,10 +29,14 @@
 package faapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/html"
@@ -40,8 +44,16 @@ import (
 
 type (
 	User struct {
-		c    *Client
-		name string
+		c        *Client
+		name     string
+		banner   *[]byte
+		avatar   *[]byte
+		birthday *time.Time
+		profile  *UserProfile
+		// journalPages is the cached total number of journal pages
+		journalPages *uint
+		// scrapsPages is the cached total number of scraps pages
+		scrapsPages *uint
 	}
 
 	faSubmission struct {
@@ -52,6 +64,25 @@ type (
 	}
 
 	SubmissionType int
+
+	// GalleryPage is a page of a user's gallery.
+	GalleryPage struct {
+		Submissions []*Submission
+		HasPrevPage bool
+		HasNextPage bool
+		CurrentPage uint
+	}
+
+	// FavoritesPage is a page of a user's favorites.
+	FavoritesPage struct {
+		Submissions []*Submission
+		HasPrevPage bool
+		HasNextPage bool
+		CurrentPage uint
+	}
+
+	// SortOrder is the order in which a list is sorted.
+	SortOrder int
 )
 
 const (
@@ -59,7 +90,18 @@ const (
 	SubmissionTypeScraps
 )
 
+const (
+	// previewImageWorkers is how many preview images are retrieved concurrently.
+	previewImageWorkers = 4
+)
+
+const (
+	SortOrderNewest SortOrder = iota
+	SortOrderOldest
+)
+
 var (
+	backgroundURLRegexp  = regexp.MustCompile(`url\(['"]?([^'")]+)['"]?\)`)
 	journalRegexp        = regexp.MustCompile(`^/journal/(\d+)/$`)
 	galleryDataRegexp    = regexp.MustCompile(`var descriptions = (.*}});`)
 	submissionDataRegexp = regexp.MustCompile(`var submission_data = (.*}});`)
@@ -76,6 +118,18 @@ func (st SubmissionType) URI() string {
 	}
 }
 
+// query returns the query string to append to a list URL to sort it.
+func (so SortOrder) query() (string, error) {
+	switch so {
+	case SortOrderNewest:
+		return "", nil
+	case SortOrderOldest:
+		return "?order=asc", nil
+	default:
+		return "", fmt.Errorf("unknown sort order %v", so)
+	}
+}
+
 func (c *Client) NewUser(name string) *User {
 	return &User{
 		c:    c,
@@ -83,6 +137,26 @@ func (c *Client) NewUser(name string) *User {
 	}
 }
 
+// NewUserByID creates a User from a numeric user ID, by following FA's redirect to the user's profile.
+func (c *Client) NewUserByID(id int64) (*User, error) {
+	req, err := c.newRequest(http.MethodGet, fmt.Sprintf("/user/id/%d/", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.doRaw(req)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+
+	m := userLinkRegexp.FindStringSubmatch(res.Request.URL.Path)
+	if m == nil {
+		return nil, fmt.Errorf("user ID %d resolved to unexpected URL %s", id, res.Request.URL)
+	}
+	return c.NewUser(m[1]), nil
+}
+
 // GetRecent retrieves the user's most recent submissions and journal.
 // It obtains the data from the user's profile page, so the number of results is limited.
 func (u *User) GetRecent() ([]*Submission, []*Journal, error) {
@@ -96,8 +170,8 @@ func (u *User) GetRecent() ([]*Submission, []*Journal, error) {
 	}
 
 	submissions := &submissionSectionHandler{
-		c:         u.c,
-		sectionID: "gallery-latest-submissions",
+		c:          u.c,
+		sectionIDs: []string{"gallery-latest-submissions"},
 	}
 	journals := &journalHandler{
 		c: u.c,
@@ -114,38 +188,255 @@ func (u *User) GetRecent() ([]*Submission, []*Journal, error) {
 		},
 	}
 	rp.processNode(root)
+	if submissions.err != nil {
+		return subs, journs, submissions.err
+	}
+	if journals.err != nil {
+		return subs, journs, journals.err
+	}
 
-	subs = u.attachSubmissionData(submissions.subs, scripts.data)
+	subs = attachSubmissionData(submissions.subs, scripts.data)
 	journs = u.attachJournalData(journals.js)
 
 	return subs, journs, nil
 }
 
+// GetProfileBanner retrieves the banner image displayed at the top of the user's profile page.
+// Returns nil without an error if the user has not configured a banner.
+func (u *User) GetProfileBanner() ([]byte, error) {
+	if u.banner != nil {
+		return *u.banner, nil
+	}
+	log.WithField("user", u).Debug("Retrieving profile banner")
+
+	root, err := u.c.get("/user/" + u.name)
+	if err != nil {
+		return nil, err
+	}
+
+	bh := &bannerHandler{}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			bh,
+		},
+	}
+	rp.processNode(root)
+
+	var bb []byte
+	if bh.url != "" {
+		bb, err = u.c.getRaw(normalizeURL(baseURL, bh.url))
+		if err != nil {
+			return nil, err
+		}
+	}
+	u.banner = &bb
+	return bb, nil
+}
+
+// GetCommissionsOpen checks whether the user's profile indicates they are accepting commissions.
+// Returns false without an error if the profile does not show any commission information.
+func (u *User) GetCommissionsOpen() (bool, error) {
+	log.WithField("user", u).Debug("Retrieving commission status")
+
+	root, err := u.c.get("/user/" + u.name)
+	if err != nil {
+		return false, err
+	}
+
+	ch := newCommissionStatusHandler()
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			ch,
+		},
+	}
+	rp.processNode(root)
+
+	return ch.open, nil
+}
+
+// GetBirthday retrieves the birthday shown on the user's profile. Returns the zero time without an
+// error if the user does not show their birthday. If the year is not shown, it will be year 0.
+func (u *User) GetBirthday() (time.Time, error) {
+	if u.birthday != nil {
+		return *u.birthday, nil
+	}
+	log.WithField("user", u).Debug("Retrieving birthday")
+
+	root, err := u.c.get("/user/" + u.name)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	bh := &labeledValueHandler{
+		labels: []string{"Birthday", "Date of Birth"},
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			bh,
+		},
+	}
+	rp.processNode(root)
+
+	var birthday time.Time
+	if bh.value != "" {
+		birthday, err = ParseFADate(bh.value)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+	u.birthday = &birthday
+	return birthday, nil
+}
+
+// GetRecentComments retrieves the comments the user has recently made, as shown on their profile.
+// Returns an empty slice without an error if the profile does not show any.
+func (u *User) GetRecentComments() ([]*Comment, error) {
+	log.WithField("user", u).Debug("Retrieving recent comments")
+
+	root, err := u.c.get("/user/" + u.name)
+	if err != nil {
+		return nil, err
+	}
+
+	rch := &recentCommentHandler{
+		user:     u.name,
+		comments: []*Comment{},
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			rch,
+		},
+	}
+	rp.processNode(root)
+
+	return rch.comments, nil
+}
+
 // GetJournals retrieves the specified page of the user's journal. Page numbering starts at 1.
 func (u *User) GetJournals(page uint) ([]*Journal, error) {
+	journs, _, err := u.getJournals(context.Background(), page)
+	return journs, err
+}
+
+// GetAllJournals retrieves every page of the user's journal. If ctx is cancelled, the journals
+// retrieved so far are returned along with the context's error.
+func (u *User) GetAllJournals(ctx context.Context) ([]*Journal, error) {
+	var all []*Journal
+	for page := uint(1); ; page++ {
+		if err := ctx.Err(); err != nil {
+			return all, err
+		}
+
+		journs, _, err := u.getJournals(ctx, page)
+		if err != nil {
+			return all, err
+		}
+		log.WithField("user", u).WithField("page", page).WithField("count", len(journs)).Debug("Retrieved journal page")
+		if len(journs) == 0 {
+			return all, nil
+		}
+		all = append(all, journs...)
+	}
+}
+
+// GetTotalJournalPages determines how many pages of journals the user has, from the pager on the
+// first page of their journal. The result is cached.
+func (u *User) GetTotalJournalPages() (uint, error) {
+	if u.journalPages != nil {
+		return *u.journalPages, nil
+	}
+
+	log.WithField("user", u).Debug("Retrieving journal page count")
+	uri := fmt.Sprintf("/journals/%s/", u.name)
+	root, err := u.c.get(uri + "1/")
+	if err != nil {
+		return 0, err
+	}
+
+	journals := &journalHandler{
+		c: u.c,
+	}
+	pages := &pageCountHandler{
+		prefix: uri,
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			journals,
+			pages,
+		},
+	}
+	rp.processNode(root)
+	if journals.err != nil {
+		return 0, journals.err
+	}
+	total := uint(0)
+	if len(journals.js) > 0 {
+		total, err = pages.countPages(func(page uint) (bool, bool, error) {
+			journs, hasNext, err := u.getJournals(context.Background(), page)
+			return len(journs) > 0, hasNext, err
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	u.journalPages = &total
+	return total, nil
+}
+
+// GetJournalReverse retrieves the specified page of the user's journal, counting from the oldest
+// journals, in oldest-first order. Page numbering starts at 1.
+func (u *User) GetJournalReverse(page uint) ([]*Journal, error) {
+	if page == 0 {
+		page = 1
+	}
+	total, err := u.GetTotalJournalPages()
+	if err != nil {
+		return nil, err
+	}
+	if page > total {
+		return nil, nil
+	}
+
+	journs, err := u.GetJournals(total + 1 - page)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(journs)-1; i < j; i, j = i+1, j-1 {
+		journs[i], journs[j] = journs[j], journs[i]
+	}
+	return journs, nil
+}
+
+// getJournals retrieves the specified page of the user's journal, and whether there is a next page.
+func (u *User) getJournals(ctx context.Context, page uint) ([]*Journal, bool, error) {
 	if page == 0 {
 		page = 1
 	}
 	log.WithField("user", u).WithField("page", page).Debug("Retrieving journals")
 
 	var journs []*Journal
-	root, err := u.c.get(fmt.Sprintf("/journals/%s/%d/", u.name, page))
+	root, err := u.c.getWithContext(ctx, fmt.Sprintf("/journals/%s/%d/", u.name, page))
 	if err != nil {
-		return journs, err
+		return journs, false, err
 	}
 
 	journals := &journalHandler{
 		c: u.c,
 	}
+	pagination := &paginationHandler{}
 	rp := &subtreeProcessor{
 		tagHandlers: []tagHandler{
 			journals,
+			pagination,
 		},
 	}
 	rp.processNode(root)
+	if journals.err != nil {
+		return journs, false, journals.err
+	}
 	journs = u.attachJournalData(journals.js)
 
-	return journs, nil
+	return journs, pagination.hasNext, nil
 }
 
 // GetSubmissions retrieves the specified page of the user's gallery. Page numbering starts at 1.
@@ -154,40 +445,497 @@ func (u *User) GetSubmissions(page uint) ([]*Submission, error) {
 	return u.GetGallery(SubmissionTypeGallery, page)
 }
 
+// GetScraps retrieves the specified page of the user's scraps. Page numbering starts at 1.
+// NOTE: Rating information is currently not provided on the submissions.
+func (u *User) GetScraps(page uint) ([]*Submission, error) {
+	return u.GetGallery(SubmissionTypeScraps, page)
+}
+
+// AllScrapsSubmissions retrieves every page of the user's scraps, sending each submission to the
+// returned channel as it is retrieved. The error channel receives at most one error, and both
+// channels are closed when iteration stops. Cancelling ctx stops iteration.
+func (u *User) AllScrapsSubmissions(ctx context.Context) (<-chan *Submission, <-chan error) {
+	subCh := make(chan *Submission)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(errCh)
+		defer close(subCh)
+
+		for page := uint(1); ; page++ {
+			subs, err := u.getGallery(ctx, SubmissionTypeScraps, page)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			if len(subs) == 0 {
+				return
+			}
+			for _, sub := range subs {
+				select {
+				case subCh <- sub:
+				case <-ctx.Done():
+					errCh <- ctx.Err()
+					return
+				}
+			}
+		}
+	}()
+
+	return subCh, errCh
+}
+
+// GetTotalScrapsPages determines how many pages of scraps the user has, from the pager on the first
+// page of their scraps. The result is cached.
+func (u *User) GetTotalScrapsPages() (uint, error) {
+	if u.scrapsPages != nil {
+		return *u.scrapsPages, nil
+	}
+
+	log.WithField("user", u).Debug("Retrieving scraps page count")
+	uri := fmt.Sprintf("/%s/%s/", SubmissionTypeScraps.URI(), u.name)
+	root, err := u.c.get(uri + "1/")
+	if err != nil {
+		return 0, err
+	}
+
+	submissions := &submissionSectionHandler{
+		c:          u.c,
+		sectionIDs: []string{"gallery-gallery"},
+	}
+	pages := &pageCountHandler{
+		prefix: uri,
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			submissions,
+			pages,
+		},
+	}
+	rp.processNode(root)
+	if submissions.err != nil {
+		return 0, submissions.err
+	}
+	total := uint(0)
+	if len(submissions.subs) > 0 {
+		total, err = pages.countPages(func(page uint) (bool, bool, error) {
+			gp, err := u.getGalleryPage(context.Background(), SubmissionTypeScraps, page)
+			if err != nil {
+				return false, false, err
+			}
+			return len(gp.Submissions) > 0, gp.HasNextPage, nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	u.scrapsPages = &total
+	return total, nil
+}
+
 // GetGallery retrieves the specified page of the user's gallery of the specified type. Page numbering starts at 1.
 // NOTE: Rating information is currently not provided on the submissions.
 func (u *User) GetGallery(st SubmissionType, page uint) ([]*Submission, error) {
+	return u.getGallery(context.Background(), st, page)
+}
+
+// GetGalleryCount retrieves the total number of submissions in the user's gallery, as shown on the
+// first page of their gallery. Returns -1 without an error if the count is not shown.
+func (u *User) GetGalleryCount() (int, error) {
+	log.WithField("user", u).Debug("Retrieving gallery count")
+	root, err := u.c.get(fmt.Sprintf("/%s/%s/", SubmissionTypeGallery.URI(), u.name))
+	if err != nil {
+		return 0, err
+	}
+
+	gch := &labeledValueHandler{
+		labels: []string{"Submissions"},
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			gch,
+		},
+	}
+	rp.processNode(root)
+
+	m := countRegexp.FindString(gch.value)
+	if m == "" {
+		return -1, nil
+	}
+	return parseCount(m), nil
+}
+
+// GetAllGallery retrieves every page of the user's gallery. If ctx is cancelled, the submissions
+// retrieved so far are returned along with the context's error.
+func (u *User) GetAllGallery(ctx context.Context) ([]*Submission, error) {
+	return u.GetGalleryWithProgress(ctx, SubmissionTypeGallery, func(page uint, fetched []*Submission) {
+		log.WithField("user", u).WithField("page", page).WithField("count", len(fetched)).Debug("Retrieved gallery page")
+	})
+}
+
+// GetGalleryAsMap retrieves every page of the user's gallery, keyed by submission ID.
+func (u *User) GetGalleryAsMap(ctx context.Context) (map[int64]*Submission, error) {
+	subs, err := u.GetAllGallery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*Submission, len(subs))
+	for _, sub := range subs {
+		byID[sub.ID] = sub
+	}
+	return byID, nil
+}
+
+// GetGalleryWithProgress retrieves every page of the user's gallery of the specified type. onPage, if
+// not nil, is called after each page is retrieved with the page number and the submissions on it.
+// If ctx is cancelled, the submissions retrieved so far are returned along with the context's error.
+func (u *User) GetGalleryWithProgress(ctx context.Context, st SubmissionType,
+	onPage func(page uint, fetched []*Submission)) ([]*Submission, error) {
+	var all []*Submission
+	for page := uint(1); ; page++ {
+		if err := ctx.Err(); err != nil {
+			return all, err
+		}
+
+		subs, err := u.getGallery(ctx, st, page)
+		if err != nil {
+			return all, err
+		}
+		if len(subs) == 0 {
+			return all, nil
+		}
+		all = append(all, subs...)
+		if onPage != nil {
+			onPage(page, subs)
+		}
+	}
+}
+
+// GetSubmissionsPage retrieves the specified page of the user's gallery, along with pagination
+// information. Page numbering starts at 1.
+func (u *User) GetSubmissionsPage(page uint) (*GalleryPage, error) {
+	return u.getGalleryPage(context.Background(), SubmissionTypeGallery, page)
+}
+
+// GetGalleryWithPreviewImages retrieves the specified page of the user's gallery of the specified
+// type, and retrieves the preview image of each submission concurrently so that PreviewImage returns
+// immediately. Preview image requests are subject to the rate limit. Page numbering starts at 1.
+func (u *User) GetGalleryWithPreviewImages(ctx context.Context, st SubmissionType, page uint) ([]*Submission, error) {
+	subs, err := u.getGallery(ctx, st, page)
+	if err != nil {
+		return nil, err
+	}
+
+	work := make(chan *Submission)
+	errs := make(chan error, len(subs))
+	var wg sync.WaitGroup
+	for i := 0; i < previewImageWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sub := range work {
+				// each attempt at a preview size is rate limited, not just each submission
+				if _, err := sub.fetchPreviewImage(ctx, true); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+
+	for _, sub := range subs {
+		work <- sub
+	}
+	close(work)
+	wg.Wait()
+	close(errs)
+
+	// report the first error, if any
+	if err, ok := <-errs; ok {
+		return subs, err
+	}
+	return subs, nil
+}
+
+// GetNewSubmissionsSince retrieves the submissions in the user's gallery that are newer than the
+// submission with ID sinceID, newest first. If ctx is cancelled, the submissions retrieved so far are
+// returned along with the context's error.
+func (u *User) GetNewSubmissionsSince(sinceID int64, ctx context.Context) ([]*Submission, error) {
+	var subs []*Submission
+	for page := uint(1); ; page++ {
+		if err := ctx.Err(); err != nil {
+			return subs, err
+		}
+
+		fetched, err := u.getGallery(ctx, SubmissionTypeGallery, page)
+		if err != nil {
+			return subs, err
+		}
+		if len(fetched) == 0 {
+			return subs, nil
+		}
+		for _, sub := range fetched {
+			if sub.ID <= sinceID {
+				return subs, nil
+			}
+			subs = append(subs, sub)
+		}
+	}
+}
+
+// GetNewGallerySubmissions retrieves pages of the user's gallery, sending each submission whose ID is
+// not in knownIDs to the returned channel. Iteration stops without an error once every submission on a
+// page is known, or there are no more pages. The error channel receives at most one error, and both
+// channels are closed when iteration stops. Cancelling ctx stops iteration.
+func (u *User) GetNewGallerySubmissions(ctx context.Context, knownIDs map[int64]struct{}) (<-chan *Submission, <-chan error) {
+	subCh := make(chan *Submission)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(errCh)
+		defer close(subCh)
+
+		for page := uint(1); ; page++ {
+			subs, err := u.getGallery(ctx, SubmissionTypeGallery, page)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			if len(subs) == 0 {
+				return
+			}
+
+			allKnown := true
+			for _, sub := range subs {
+				if _, ok := knownIDs[sub.ID]; ok {
+					continue
+				}
+				allKnown = false
+				select {
+				case subCh <- sub:
+				case <-ctx.Done():
+					errCh <- ctx.Err()
+					return
+				}
+			}
+			if allKnown {
+				return
+			}
+		}
+	}()
+
+	return subCh, errCh
+}
+
+func (u *User) getGallery(ctx context.Context, st SubmissionType, page uint) ([]*Submission, error) {
+	gp, err := u.getGalleryPage(ctx, st, page)
+	if err != nil {
+		return nil, err
+	}
+	return gp.Submissions, nil
+}
+
+func (u *User) getGalleryPage(ctx context.Context, st SubmissionType, page uint) (*GalleryPage, error) {
 	if page == 0 {
 		page = 1
 	}
 	log.WithField("user", u).WithField("page", page).Debugf("Retrieving submissions %s", st.URI())
 
+	return u.getGalleryPageURI(ctx, fmt.Sprintf("/%s/%s/", st.URI(), u.name), page)
+}
+
+// getGalleryPageURI retrieves the specified page of a gallery listing, such as a gallery or a folder,
+// located at uri.
+func (u *User) getGalleryPageURI(ctx context.Context, uri string, page uint) (*GalleryPage, error) {
+	root, err := u.c.getWithContext(ctx, fmt.Sprintf("%s%d/", uri, page))
+	if err != nil {
+		return nil, err
+	}
+
+	submissions := &submissionSectionHandler{
+		c:          u.c,
+		sectionIDs: []string{"gallery-gallery"},
+	}
+	scripts := &scriptHandler{
+		regexp: galleryDataRegexp,
+	}
+	pagination := &paginationHandler{}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			submissions,
+			scripts,
+			pagination,
+		},
+	}
+	rp.processNode(root)
+	if submissions.err != nil {
+		return nil, submissions.err
+	}
+
+	return &GalleryPage{
+		Submissions: attachSubmissionData(submissions.subs, scripts.data),
+		HasPrevPage: pagination.hasPrev,
+		HasNextPage: pagination.hasNext,
+		CurrentPage: page,
+	}, nil
+}
+
+// GetFavorites retrieves the specified page of the user's favorites. Page numbering starts at 1.
+func (u *User) GetFavorites(page uint) ([]*Submission, error) {
+	return u.getFavorites(context.Background(), page, SortOrderNewest)
+}
+
+// GetFavoritesWithSort retrieves the specified page of the user's favorites in the specified order.
+// Page numbering starts at 1.
+func (u *User) GetFavoritesWithSort(page uint, order SortOrder) ([]*Submission, error) {
+	return u.getFavorites(context.Background(), page, order)
+}
+
+// GetAllFavorites retrieves every page of the user's favorites. If ctx is cancelled, the submissions
+// retrieved so far are returned along with the context's error.
+func (u *User) GetAllFavorites(ctx context.Context) ([]*Submission, error) {
+	var all []*Submission
+	for page := uint(1); ; page++ {
+		if err := ctx.Err(); err != nil {
+			return all, err
+		}
+
+		subs, err := u.getFavorites(ctx, page, SortOrderNewest)
+		if err != nil {
+			return all, err
+		}
+		log.WithField("user", u).WithField("page", page).WithField("count", len(subs)).Debug("Retrieved favorites page")
+		if len(subs) == 0 {
+			return all, nil
+		}
+		all = append(all, subs...)
+	}
+}
+
+// GetFavoritesStream retrieves every page of the user's favorites, sending each submission to the
+// returned channel as it is retrieved. The error channel receives at most one error, and both
+// channels are closed when iteration stops. Cancelling ctx stops iteration.
+func (u *User) GetFavoritesStream(ctx context.Context) (<-chan *Submission, <-chan error) {
+	subCh := make(chan *Submission)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(errCh)
+		defer close(subCh)
+
+		for page := uint(1); ; page++ {
+			subs, err := u.getFavorites(ctx, page, SortOrderNewest)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			if len(subs) == 0 {
+				return
+			}
+			for _, sub := range subs {
+				select {
+				case subCh <- sub:
+				case <-ctx.Done():
+					errCh <- ctx.Err()
+					return
+				}
+			}
+		}
+	}()
+
+	return subCh, errCh
+}
+
+// GetRecentFavoritedBy retrieves the submissions by this user which are in favoritedByUser's favorites.
+// Every page of favoritedByUser's favorites is retrieved.
+func (u *User) GetRecentFavoritedBy(favoritedByUser string) ([]*Submission, error) {
+	return u.GetRecentFavoritedByWithContext(context.Background(), favoritedByUser)
+}
+
+// GetRecentFavoritedByWithContext is GetRecentFavoritedBy with a context. If ctx is cancelled, the
+// submissions found so far are returned along with the context's error.
+func (u *User) GetRecentFavoritedByWithContext(ctx context.Context, favoritedByUser string) ([]*Submission, error) {
+	log.WithField("user", u).WithField("favoritedBy", favoritedByUser).Debug("Retrieving favorited submissions")
+	fu := u.c.NewUser(favoritedByUser)
+
 	var subs []*Submission
-	root, err := u.c.get(fmt.Sprintf("/%s/%s/%d/", st.URI(), u.name, page))
+	for page := uint(1); ; page++ {
+		if err := ctx.Err(); err != nil {
+			return subs, err
+		}
+
+		favs, err := fu.getFavorites(ctx, page, SortOrderNewest)
+		if err != nil {
+			return subs, err
+		}
+		if len(favs) == 0 {
+			return subs, nil
+		}
+		for _, fav := range favs {
+			if strings.EqualFold(fav.User, u.name) {
+				subs = append(subs, fav)
+			}
+		}
+	}
+}
+
+// GetFavoritesPage retrieves the specified page of the user's favorites, along with pagination
+// information. Page numbering starts at 1.
+func (u *User) GetFavoritesPage(page uint) (*FavoritesPage, error) {
+	return u.getFavoritesPage(context.Background(), page, SortOrderNewest)
+}
+
+func (u *User) getFavorites(ctx context.Context, page uint, order SortOrder) ([]*Submission, error) {
+	fp, err := u.getFavoritesPage(ctx, page, order)
 	if err != nil {
-		return subs, err
+		return nil, err
+	}
+	return fp.Submissions, nil
+}
+
+func (u *User) getFavoritesPage(ctx context.Context, page uint, order SortOrder) (*FavoritesPage, error) {
+	if page == 0 {
+		page = 1
+	}
+	log.WithField("user", u).WithField("page", page).Debug("Retrieving favorites")
+
+	query, err := order.query()
+	if err != nil {
+		return nil, err
+	}
+	root, err := u.c.getWithContext(ctx, fmt.Sprintf("/favorites/%s/%d/%s", u.name, page, query))
+	if err != nil {
+		return nil, err
 	}
 
 	submissions := &submissionSectionHandler{
-		c:         u.c,
-		sectionID: "gallery-gallery",
+		c:          u.c,
+		sectionIDs: []string{"gallery-favorites"},
 	}
 	scripts := &scriptHandler{
 		regexp: galleryDataRegexp,
 	}
+	pagination := &paginationHandler{}
 	rp := &subtreeProcessor{
 		tagHandlers: []tagHandler{
 			submissions,
 			scripts,
+			pagination,
 		},
 	}
 	rp.processNode(root)
+	if submissions.err != nil {
+		return nil, submissions.err
+	}
 
-	subs = u.attachSubmissionData(submissions.subs, scripts.data)
-	return subs, nil
+	return &FavoritesPage{
+		Submissions: attachSubmissionData(submissions.subs, scripts.data),
+		HasPrevPage: pagination.hasPrev,
+		HasNextPage: pagination.hasNext,
+		CurrentPage: page,
+	}, nil
 }
 
-func (u *User) attachSubmissionData(subs []*Submission, data map[int64]faSubmission) []*Submission {
+func attachSubmissionData(subs []*Submission, data map[int64]faSubmission) []*Submission {
 	for i := range subs {
 		id := subs[i].ID
 		if data[id].Rating != "" {
@@ -228,15 +976,26 @@ func (s *scriptHandler) process(n *html.Node) bool {
 	return false
 }
 
-// submissionSectionHandler finds and extracts the recent submissionHandler section
+// submissionSectionHandler finds and extracts the submissions in the section with sectionID or any of
+// sectionIDs, or in every section with sectionClass
 type submissionSectionHandler struct {
-	c         *Client
-	sectionID string
-	subs      []*Submission
+	c            *Client
+	sectionID    string
+	sectionIDs   []string
+	sectionClass string
+	subs         []*Submission
+	// err is the first error encountered parsing a submission
+	err error
 }
 
 func (sh *submissionSectionHandler) matches(n *html.Node) bool {
-	return checkNodeTagNameAndID(n, "section", sh.sectionID)
+	if sh.sectionClass != "" && checkNodeTagNameAndClass(n, "section", sh.sectionClass) {
+		return true
+	}
+	if sh.sectionID != "" && checkNodeSectionID(n, []string{sh.sectionID}) {
+		return true
+	}
+	return checkNodeSectionID(n, sh.sectionIDs)
 }
 
 func (sh *submissionSectionHandler) process(n *html.Node) bool {
@@ -250,7 +1009,10 @@ func (sh *submissionSectionHandler) process(n *html.Node) bool {
 	}
 	p.processNode(n)
 
-	sh.subs = s.subs
+	sh.subs = append(sh.subs, s.subs...)
+	if sh.err == nil {
+		sh.err = s.err
+	}
 	return false
 }
 
@@ -258,6 +1020,8 @@ func (sh *submissionSectionHandler) process(n *html.Node) bool {
 type submissionHandler struct {
 	c    *Client
 	subs []*Submission
+	// err is the first error encountered parsing a submission
+	err error
 }
 
 func (*submissionHandler) matches(n *html.Node) bool {
@@ -272,9 +1036,17 @@ func (s *submissionHandler) process(n *html.Node) bool {
 		},
 	}
 	p.processNode(n)
+
+	id, err := parseSubmissionID(findAttribute(n.Attr, "id"))
+	if err != nil {
+		if s.err == nil {
+			s.err = fmt.Errorf("unparseable submission: %w", err)
+		}
+		return false
+	}
 	s.subs = append(s.subs, &Submission{
 		c:  s.c,
-		ID: parseSubmissionID(findAttribute(n.Attr, "id")),
+		ID: id,
 		// gallery pages only provide the rating as a class attribute
 		Rating:     Rating(strings.Replace(strings.Split(findAttribute(n.Attr, "class"), " ")[0], "r-", "", 1)),
 		PreviewURL: si.url,
@@ -291,7 +1063,47 @@ func (*submissionImageHandler) matches(n *html.Node) bool {
 }
 
 func (si *submissionImageHandler) process(n *html.Node) bool {
-	si.url = "https:" + findAttribute(n.Attr, "src")
+	si.url = normalizeURL(baseURL, findAttribute(n.Attr, "src"))
+	return false
+}
+
+// bannerHandler finds the profile banner image, either as an img or as a CSS background
+type bannerHandler struct {
+	url string
+}
+
+func (*bannerHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "div", "userpage-banner")
+}
+
+func (bh *bannerHandler) process(n *html.Node) bool {
+	if m := backgroundURLRegexp.FindStringSubmatch(findAttribute(n.Attr, "style")); m != nil {
+		bh.url = m[1]
+		return false
+	}
+	if img := findChild(n, "img", 0); img != nil {
+		bh.url = findAttribute(img.Attr, "src")
+	}
+	return false
+}
+
+// commissionStatusHandler finds the "Accepting Commissions" label and reads the value after it
+type commissionStatusHandler struct {
+	labeledValueHandler
+	open bool
+}
+
+func newCommissionStatusHandler() *commissionStatusHandler {
+	return &commissionStatusHandler{
+		labeledValueHandler: labeledValueHandler{
+			labels: []string{"Accepting Commissions"},
+		},
+	}
+}
+
+func (ch *commissionStatusHandler) process(n *html.Node) bool {
+	ch.labeledValueHandler.process(n)
+	ch.open = strings.EqualFold(ch.value, "yes") || strings.EqualFold(ch.value, "open")
 	return false
 }
 
@@ -299,6 +1111,8 @@ func (si *submissionImageHandler) process(n *html.Node) bool {
 type journalHandler struct {
 	c  *Client
 	js []*Journal
+	// err is the first error encountered parsing a journal link
+	err error
 }
 
 func (j *journalHandler) matches(n *html.Node) bool {
@@ -318,9 +1132,15 @@ func (j *journalHandler) matches(n *html.Node) bool {
 
 func (j *journalHandler) process(n *html.Node) bool {
 	href := findAttribute(n.Attr, "href")
-	id := journalRegexp.FindStringSubmatch(href)[1]
+	id, err := parseSubmissionID(journalRegexp.FindStringSubmatch(href)[1])
+	if err != nil {
+		if j.err == nil {
+			j.err = fmt.Errorf("unparseable journal: %w", err)
+		}
+		return false
+	}
 	j.js = append(j.js, &Journal{
-		ID:    parseSubmissionID(id),
+		ID:    id,
 		Title: n.FirstChild.Data,
 	})
 	return false
@@ -0,0 +1,115 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"testing"
+)
+
+func TestGalleryHandlers(t *testing.T) {
+	submissions := &submissionSectionHandler{
+		sectionID: "gallery-gallery",
+	}
+	scripts := &scriptHandler{
+		regexp: galleryDataRegexp,
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			submissions,
+			scripts,
+		},
+	}
+	rp.processNode(loadFixture(t, "gallery.html"))
+	if submissions.err != nil {
+		t.Fatal(submissions.err)
+	}
+
+	subs := attachSubmissionData(submissions.subs, scripts.data)
+	want := []Submission{
+		{ID: 101, Rating: RatingGeneral, Title: "First", User: "artist", PreviewURL: "https://t.furaffinity.net/101@200-1577977440.jpg"},
+		// the rating in the script data takes precedence over the class
+		{ID: 102, Rating: RatingAdult, Title: "Second", User: "artist", PreviewURL: "https://t.furaffinity.net/102@200-1577977441.jpg"},
+	}
+	if len(subs) != len(want) {
+		t.Fatalf("got %d submissions, want %d", len(subs), len(want))
+	}
+	for i, s := range subs {
+		w := want[i]
+		if s.ID != w.ID || s.Rating != w.Rating || s.Title != w.Title || s.User != w.User || s.PreviewURL != w.PreviewURL {
+			t.Errorf("submission %d = %+v, want %+v", i, *s, w)
+		}
+	}
+}
+
+func TestSubmissionHandlerUnparseableID(t *testing.T) {
+	root := parseFragment(t, `<section id="gallery-gallery"><figure id="sid-abc"></figure><figure id="sid-1"></figure></section>`)
+	submissions := &submissionSectionHandler{
+		sectionID: "gallery-gallery",
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			submissions,
+		},
+	}
+	rp.processNode(root)
+
+	if submissions.err == nil {
+		t.Error("err = nil, want an error for the unparseable ID")
+	}
+	if len(submissions.subs) != 1 || submissions.subs[0].ID != 1 {
+		t.Errorf("subs = %+v, want only submission 1", submissions.subs)
+	}
+}
+
+func TestJournalHandler(t *testing.T) {
+	journals := &journalHandler{}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			journals,
+		},
+	}
+	rp.processNode(loadFixture(t, "journals.html"))
+	if journals.err != nil {
+		t.Fatal(journals.err)
+	}
+
+	// the "Read more..." and "Comments" links must not be counted as journals
+	want := []Journal{
+		{ID: 201, Title: "Newest journal"},
+		{ID: 200, Title: "Older journal"},
+	}
+	if len(journals.js) != len(want) {
+		t.Fatalf("got %d journals, want %d: %v", len(journals.js), len(want), journals)
+	}
+	for i, j := range journals.js {
+		if j.ID != want[i].ID || j.Title != want[i].Title {
+			t.Errorf("journal %d = %d %q, want %d %q", i, j.ID, j.Title, want[i].ID, want[i].Title)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+/*
+ *
+ * Copyright (c) 2018-2019, Andy Janata
+ * All rights reserved.
+ *
+ * Redistribution and use in source and binary forms, with or without modification, are permitted
+ * provided that the following conditions are met:
+ *
+ * * Redistributions of source code must retain the above copyright notice, this list of conditions
+ *   and the following disclaimer.
+ * * Redistributions in binary form must reproduce the above copyright notice, this list of
+ *   conditions and the following disclaimer in the documentation and/or other materials provided
+ *   with the distribution.
+ * * Neither the name of the copyright holder nor the names of its contributors may be used to
+ *   endorse or promote products derived from this software without specific prior written
+ *   permission.
+ *
+ * THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR
+ * IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND
+ * FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR
+ * CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+ * DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+ * DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+ * WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY
+ * WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+ *
+ */
+
+package faapi
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+)
+
+// WatchingPage is a page of users from a user's watch list, either the users they are watching or the
+// users watching them.
+type WatchingPage struct {
+	Users       []*User
+	HasPrevPage bool
+	HasNextPage bool
+	CurrentPage uint
+}
+
+var (
+	countRegexp    = regexp.MustCompile(`\d[\d,]*`)
+	userLinkRegexp = regexp.MustCompile(`^/user/([^/]+)/?$`)
+)
+
+// GetWatching retrieves the specified page of the users this user is watching. Page numbering starts at 1.
+func (u *User) GetWatching(page uint) ([]*User, error) {
+	wp, err := u.getWatchList(context.Background(), "by", page)
+	if err != nil {
+		return nil, err
+	}
+	return wp.Users, nil
+}
+
+// GetWatchingPage retrieves the specified page of the users this user is watching, along with
+// pagination information. Page numbering starts at 1.
+func (u *User) GetWatchingPage(page uint) (*WatchingPage, error) {
+	return u.getWatchList(context.Background(), "by", page)
+}
+
+// GetWatchers retrieves the specified page of the users watching this user. Page numbering starts at 1.
+func (u *User) GetWatchers(page uint) ([]*User, error) {
+	wp, err := u.getWatchList(context.Background(), "to", page)
+	if err != nil {
+		return nil, err
+	}
+	return wp.Users, nil
+}
+
+// GetWatchersPage retrieves the specified page of the users watching this user, along with
+// pagination information. Page numbering starts at 1.
+func (u *User) GetWatchersPage(page uint) (*WatchingPage, error) {
+	return u.getWatchList(context.Background(), "to", page)
+}
+
+// GetAllWatching retrieves every page of the users this user is watching. If ctx is cancelled, the
+// users retrieved so far are returned along with the context's error.
+func (u *User) GetAllWatching(ctx context.Context) ([]*User, error) {
+	return u.getAllWatchList(ctx, "by")
+}
+
+// GetAllWatchers retrieves every page of the users watching this user. If ctx is cancelled, the users
+// retrieved so far are returned along with the context's error.
+func (u *User) GetAllWatchers(ctx context.Context) ([]*User, error) {
+	return u.getAllWatchList(ctx, "to")
+}
+
+// GetWatcherCount retrieves the number of users watching this user, as shown on their profile.
+func (u *User) GetWatcherCount() (int, error) {
+	return u.getWatchCount("to")
+}
+
+// GetWatchingCount retrieves the number of users this user is watching, as shown on their profile.
+func (u *User) GetWatchingCount() (int, error) {
+	return u.getWatchCount("by")
+}
+
+func (u *User) getWatchCount(direction string) (int, error) {
+	log.WithField("user", u).Debugf("Retrieving watch count %s", direction)
+
+	root, err := u.c.get("/user/" + u.name)
+	if err != nil {
+		return 0, err
+	}
+
+	wch := &watchCountHandler{
+		direction: direction,
+	}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			wch,
+		},
+	}
+	rp.processNode(root)
+
+	if !wch.found {
+		return 0, fmt.Errorf("watch count not found for user %s", u.name)
+	}
+	return wch.count, nil
+}
+
+// getWatchList retrieves a page of the watch list in the given direction: "by" for the users this
+// user is watching, or "to" for the users watching this user.
+func (u *User) getWatchList(ctx context.Context, direction string, page uint) (*WatchingPage, error) {
+	if page == 0 {
+		page = 1
+	}
+	log.WithField("user", u).WithField("page", page).Debugf("Retrieving watch list %s", direction)
+
+	root, err := u.c.getWithContext(ctx, fmt.Sprintf("/watchlist/%s/%s/%d/", direction, u.name, page))
+	if err != nil {
+		return nil, err
+	}
+
+	wh := &watchListHandler{
+		c: u.c,
+	}
+	pagination := &paginationHandler{}
+	rp := &subtreeProcessor{
+		tagHandlers: []tagHandler{
+			wh,
+			pagination,
+		},
+	}
+	rp.processNode(root)
+
+	return &WatchingPage{
+		Users:       wh.users,
+		HasPrevPage: pagination.hasPrev,
+		HasNextPage: pagination.hasNext,
+		CurrentPage: page,
+	}, nil
+}
+
+func (u *User) getAllWatchList(ctx context.Context, direction string) ([]*User, error) {
+	var all []*User
+	for page := uint(1); ; page++ {
+		if err := ctx.Err(); err != nil {
+			return all, err
+		}
+
+		wp, err := u.getWatchList(ctx, direction, page)
+		if err != nil {
+			return all, err
+		}
+		if len(wp.Users) == 0 {
+			return all, nil
+		}
+		all = append(all, wp.Users...)
+	}
+}
+
+// watchListHandler finds the watch list and extracts each user in it
+type watchListHandler struct {
+	c     *Client
+	users []*User
+}
+
+func (*watchListHandler) matches(n *html.Node) bool {
+	return checkNodeTagNameAndClass(n, "div", "watch-list")
+}
+
+func (wh *watchListHandler) process(n *html.Node) bool {
+	ul := &userLinkHandler{}
+	p := subtreeProcessor{
+		tagHandlers: []tagHandler{
+			ul,
+		},
+	}
+	p.processNode(n)
+
+	for _, name := range ul.names {
+		wh.users = append(wh.users, wh.c.NewUser(name))
+	}
+	return false
+}
+
+// userLinkHandler extracts the username from each link to a user's profile
+type userLinkHandler struct {
+	names []string
+}
+
+func (*userLinkHandler) matches(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "a" && userLinkRegexp.MatchString(findAttribute(n.Attr, "href"))
+}
+
+func (ul *userLinkHandler) process(n *html.Node) bool {
+	ul.names = append(ul.names, userLinkRegexp.FindStringSubmatch(findAttribute(n.Attr, "href"))[1])
+	return false
+}
+
+// watchCountHandler extracts the count from the profile's link to a watch list, e.g. "Watched by (1,234)"
+type watchCountHandler struct {
+	direction string
+	found     bool
+	count     int
+}
+
+func (wch *watchCountHandler) matches(n *html.Node) bool {
+	return !wch.found && n.Type == html.ElementNode && n.Data == "a" &&
+		strings.HasPrefix(findAttribute(n.Attr, "href"), "/watchlist/"+wch.direction+"/")
+}
+
+func (wch *watchCountHandler) process(n *html.Node) bool {
+	if m := countRegexp.FindString(getText(n)); m != "" {
+		wch.found = true
+		wch.count = parseCount(m)
+	}
+	return false
+}